@@ -0,0 +1,134 @@
+// Package hid builds and parses USB HID report descriptors. It has no
+// TinyGo dependencies so descriptors can be checked on the host.
+package hid
+
+// Item types.
+const (
+	typeMain   = 0
+	typeGlobal = 1
+	typeLocal  = 2
+)
+
+// Main item tags.
+const (
+	tagInput         = 0x8
+	tagOutput        = 0x9
+	tagCollection    = 0xa
+	tagFeature       = 0xb
+	tagEndCollection = 0xc
+)
+
+// Global item tags.
+const (
+	tagUsagePage       = 0x0
+	tagLogicalMinimum  = 0x1
+	tagLogicalMaximum  = 0x2
+	tagPhysicalMinimum = 0x3
+	tagPhysicalMaximum = 0x4
+	tagUnitExponent    = 0x5
+	tagUnit            = 0x6
+	tagReportSize      = 0x7
+	tagReportID        = 0x8
+	tagReportCount     = 0x9
+	tagPush            = 0xa
+	tagPop             = 0xb
+)
+
+// Local item tags.
+const (
+	tagUsage        = 0x0
+	tagUsageMinimum = 0x1
+	tagUsageMaximum = 0x2
+)
+
+// Input, Output and Feature flags.
+const (
+	Data     = 0x00
+	Const    = 0x01
+	Array    = 0x00
+	Var      = 0x02
+	Abs      = 0x00
+	Rel      = 0x04
+	Wrap     = 0x08
+	NonLin   = 0x10
+	NullSt   = 0x40
+	Volatile = 0x80
+)
+
+// Collection kinds.
+const (
+	CollectionPhysical    = 0x00
+	CollectionApplication = 0x01
+	CollectionLogical     = 0x02
+)
+
+// Usage pages.
+const (
+	PageGenericDesktop = 0x01
+	PageSimulation     = 0x02
+	PageKeyboard       = 0x07
+	PageLED            = 0x08
+	PageButton         = 0x09
+	PageDigitizer      = 0x0d
+	PageVendor         = 0xff00
+)
+
+// Generic desktop usages.
+const (
+	UsagePointer   = 0x01
+	UsageMouse     = 0x02
+	UsageJoystick  = 0x04
+	UsageGamepad   = 0x05
+	UsageKeyboard  = 0x06
+	UsageX         = 0x30
+	UsageY         = 0x31
+	UsageZ         = 0x32
+	UsageRx        = 0x33
+	UsageRy        = 0x34
+	UsageRz        = 0x35
+	UsageSlider    = 0x36
+	UsageDial      = 0x37
+	UsageWheel     = 0x38
+	UsageHatSwitch = 0x39
+)
+
+// item encodes a short item using the smallest data size that holds v.
+func item(typ, tag byte, v int, signed bool) []byte {
+	prefix := tag<<4 | typ<<2
+	switch {
+	case signed && v >= -128 && v <= 127, !signed && v >= 0 && v <= 0xff:
+		return []byte{prefix | 1, byte(v)}
+	case signed && v >= -32768 && v <= 32767, !signed && v >= 0 && v <= 0xffff:
+		return []byte{prefix | 2, byte(v), byte(v >> 8)}
+	}
+	return []byte{prefix | 3, byte(v), byte(v >> 8), byte(v >> 16), byte(v >> 24)}
+}
+
+func Input(flags int) []byte       { return item(typeMain, tagInput, flags, false) }
+func Output(flags int) []byte      { return item(typeMain, tagOutput, flags, false) }
+func Feature(flags int) []byte     { return item(typeMain, tagFeature, flags, false) }
+func Collection(kind int) []byte   { return item(typeMain, tagCollection, kind, false) }
+func EndCollection() []byte        { return []byte{tagEndCollection << 4} }
+func UsagePage(page int) []byte    { return item(typeGlobal, tagUsagePage, page, false) }
+func LogicalMinimum(v int) []byte  { return item(typeGlobal, tagLogicalMinimum, v, true) }
+func LogicalMaximum(v int) []byte  { return item(typeGlobal, tagLogicalMaximum, v, true) }
+func PhysicalMinimum(v int) []byte { return item(typeGlobal, tagPhysicalMinimum, v, true) }
+func PhysicalMaximum(v int) []byte { return item(typeGlobal, tagPhysicalMaximum, v, true) }
+func Unit(v int) []byte            { return item(typeGlobal, tagUnit, v, false) }
+func ReportSize(bits int) []byte   { return item(typeGlobal, tagReportSize, bits, false) }
+func ReportID(id int) []byte       { return item(typeGlobal, tagReportID, id, false) }
+func ReportCount(n int) []byte     { return item(typeGlobal, tagReportCount, n, false) }
+func Push() []byte                 { return []byte{tagPush<<4 | typeGlobal<<2} }
+func Pop() []byte                  { return []byte{tagPop<<4 | typeGlobal<<2} }
+func Usage(usage int) []byte       { return item(typeLocal, tagUsage, usage, false) }
+func UsageMinimum(v int) []byte    { return item(typeLocal, tagUsageMinimum, v, false) }
+func UsageMaximum(v int) []byte    { return item(typeLocal, tagUsageMaximum, v, false) }
+
+// Append concatenates items into a descriptor.
+func Append(items ...[]byte) []byte {
+	var b []byte
+	for _, v := range items {
+		b = append(b, v...)
+	}
+	return b
+}
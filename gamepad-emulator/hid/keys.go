@@ -0,0 +1,133 @@
+package hid
+
+import "strings"
+
+// Keyboard modifier bits of a boot keyboard report.
+const (
+	ModLeftCtrl = 1 << iota
+	ModLeftShift
+	ModLeftAlt
+	ModLeftGUI
+	ModRightCtrl
+	ModRightShift
+	ModRightAlt
+	ModRightGUI
+)
+
+// Keyboard usages that have no character.
+const (
+	KeyEnter     = 0x28
+	KeyEscape    = 0x29
+	KeyBackspace = 0x2a
+	KeyTab       = 0x2b
+	KeySpace     = 0x2c
+	KeyCapsLock  = 0x39
+	KeyF1        = 0x3a // F1..F12 are consecutive
+	KeyInsert    = 0x49
+	KeyHome      = 0x4a
+	KeyPageUp    = 0x4b
+	KeyDelete    = 0x4c
+	KeyEnd       = 0x4d
+	KeyPageDown  = 0x4e
+	KeyRight     = 0x4f
+	KeyLeft      = 0x50
+	KeyDown      = 0x51
+	KeyUp        = 0x52
+	KeyLeftCtrl  = 0xe0 // modifiers 0xe0..0xe7 follow the Mod bit order
+	KeyRightGUI  = 0xe7
+)
+
+// unshifted and shifted characters of the US layout, from usage 0x1e.
+const (
+	usDigits   = "1234567890"
+	usShifted  = "!@#$%^&*()"
+	usSymbols  = "-=[]\\ ;'`,./" // from usage 0x2d, skipping the non-US key
+	usSymShift = "_+{}| :\"~<>?"
+)
+
+// KeyCode returns the keyboard usage and modifiers that type r on a US
+// layout.
+func KeyCode(r rune) (code, mods int, ok bool) {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return 0x04 + int(r-'a'), 0, true
+	case r >= 'A' && r <= 'Z':
+		return 0x04 + int(r-'A'), ModLeftShift, true
+	case r == '\n':
+		return KeyEnter, 0, true
+	case r == '\t':
+		return KeyTab, 0, true
+	case r == '\b':
+		return KeyBackspace, 0, true
+	case r == ' ':
+		return KeySpace, 0, true
+	}
+	if i := strings.IndexRune(usDigits, r); i >= 0 {
+		return 0x1e + i, 0, true
+	}
+	if i := strings.IndexRune(usShifted, r); i >= 0 {
+		return 0x1e + i, ModLeftShift, true
+	}
+	if i := strings.IndexRune(usSymbols, r); i >= 0 {
+		return 0x2d + i, 0, true
+	}
+	if i := strings.IndexRune(usSymShift, r); i >= 0 {
+		return 0x2d + i, ModLeftShift, true
+	}
+	return 0, 0, false
+}
+
+var keyNames = map[string]int{
+	"enter":     KeyEnter,
+	"esc":       KeyEscape,
+	"backspace": KeyBackspace,
+	"tab":       KeyTab,
+	"space":     KeySpace,
+	"capslock":  KeyCapsLock,
+	"insert":    KeyInsert,
+	"home":      KeyHome,
+	"pageup":    KeyPageUp,
+	"delete":    KeyDelete,
+	"end":       KeyEnd,
+	"pagedown":  KeyPageDown,
+	"right":     KeyRight,
+	"left":      KeyLeft,
+	"down":      KeyDown,
+	"up":        KeyUp,
+	"ctrl":      KeyLeftCtrl,
+	"shift":     KeyLeftCtrl + 1,
+	"alt":       KeyLeftCtrl + 2,
+	"gui":       KeyLeftCtrl + 3,
+	"rctrl":     KeyLeftCtrl + 4,
+	"rshift":    KeyLeftCtrl + 5,
+	"ralt":      KeyLeftCtrl + 6,
+	"rgui":      KeyLeftCtrl + 7,
+}
+
+// KeyByName returns the usage of a named key ("enter", "left", "ctrl",
+// "f5", ...) or of a single unshifted character such as "a" or "/".
+func KeyByName(name string) (int, bool) {
+	name = strings.ToLower(name)
+	if code, ok := keyNames[name]; ok {
+		return code, true
+	}
+	if len(name) >= 2 && len(name) <= 3 && name[0] == 'f' {
+		n := 0
+		for _, c := range name[1:] {
+			if c < '0' || c > '9' {
+				return 0, false
+			}
+			n = n*10 + int(c-'0')
+		}
+		if n >= 1 && n <= 12 {
+			return KeyF1 + n - 1, true
+		}
+		return 0, false
+	}
+	if r := []rune(name); len(r) == 1 {
+		if code, mods, ok := KeyCode(r[0]); ok && mods == 0 {
+			return code, true
+		}
+	}
+	return 0, false
+}
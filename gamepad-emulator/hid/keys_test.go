@@ -0,0 +1,73 @@
+package hid
+
+import "testing"
+
+func TestKeyCode(t *testing.T) {
+	tests := []struct {
+		r          rune
+		code, mods int
+		ok         bool
+	}{
+		{'a', 0x04, 0, true},
+		{'z', 0x1d, 0, true},
+		{'A', 0x04, ModLeftShift, true},
+		{'Z', 0x1d, ModLeftShift, true},
+		{'1', 0x1e, 0, true},
+		{'0', 0x27, 0, true},
+		{'!', 0x1e, ModLeftShift, true},
+		{')', 0x27, ModLeftShift, true},
+		{'\n', KeyEnter, 0, true},
+		{'\t', KeyTab, 0, true},
+		{'\b', KeyBackspace, 0, true},
+		{' ', KeySpace, 0, true},
+		{'-', 0x2d, 0, true},
+		{'_', 0x2d, ModLeftShift, true},
+		{'\\', 0x31, 0, true},
+		{'|', 0x31, ModLeftShift, true},
+		{';', 0x33, 0, true},
+		{':', 0x33, ModLeftShift, true},
+		{'`', 0x35, 0, true},
+		{'~', 0x35, ModLeftShift, true},
+		{'/', 0x38, 0, true},
+		{'?', 0x38, ModLeftShift, true},
+		{'é', 0, 0, false},
+		{'\r', 0, 0, false},
+	}
+	for _, tt := range tests {
+		code, mods, ok := KeyCode(tt.r)
+		if code != tt.code || mods != tt.mods || ok != tt.ok {
+			t.Errorf("KeyCode(%q) = %#x, %d, %v, want %#x, %d, %v", tt.r, code, mods, ok, tt.code, tt.mods, tt.ok)
+		}
+	}
+}
+
+func TestKeyByName(t *testing.T) {
+	tests := []struct {
+		name string
+		code int
+		ok   bool
+	}{
+		{"enter", KeyEnter, true},
+		{"Esc", KeyEscape, true},
+		{"up", KeyUp, true},
+		{"ctrl", KeyLeftCtrl, true},
+		{"rgui", KeyRightGUI, true},
+		{"f1", KeyF1, true},
+		{"F12", KeyF1 + 11, true},
+		{"f0", 0, false},
+		{"f13", 0, false},
+		{"f1x", 0, false},
+		{"a", 0x04, true},
+		{"A", 0x04, true},
+		{"/", 0x38, true},
+		{"?", 0, false},
+		{"", 0, false},
+		{"hyper", 0, false},
+	}
+	for _, tt := range tests {
+		code, ok := KeyByName(tt.name)
+		if code != tt.code || ok != tt.ok {
+			t.Errorf("KeyByName(%q) = %#x, %v, want %#x, %v", tt.name, code, ok, tt.code, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package hid
+
+// Group declares Count values of one kind in a report layout. Values use
+// Usages in order, or consecutive usages from UsageMin when Usages is nil.
+type Group struct {
+	Page        int
+	Usages      []int
+	UsageMin    int
+	Min, Max    int // logical range; a negative Min makes values signed
+	Size        int // bits per value
+	Count       int // defaults to len(Usages)
+	Flags       int // Input item flags
+	PhysicalMin int // physical range and unit, declared when Unit is set
+	PhysicalMax int
+	Unit        int
+	offset      int // bit offset in the report
+}
+
+// Layout is an input report made of groups. Each group starts on a byte
+// boundary, so the descriptor pads groups that end inside a byte.
+type Layout struct {
+	ReportID int
+	Groups   []*Group
+	size     int // bits
+}
+
+// NewLayout places groups one after another in report reportID.
+func NewLayout(reportID int, groups ...*Group) *Layout {
+	l := &Layout{ReportID: reportID, Groups: groups}
+	for _, g := range groups {
+		if g.Count == 0 {
+			g.Count = len(g.Usages)
+		}
+		g.offset = l.size
+		l.size += (g.Size*g.Count + 7) &^ 7
+	}
+	return l
+}
+
+// Buttons declares n buttons numbered from 1.
+func Buttons(n int) *Group {
+	return &Group{Page: PageButton, UsageMin: 1, Max: 1, Size: 1, Count: n, Flags: Data | Var | Abs}
+}
+
+// Hats declares n 4 bit hat switches; values outside 0..7 are centered.
+func Hats(n int) *Group {
+	g := &Group{
+		Page:        PageGenericDesktop,
+		Max:         7,
+		Size:        4,
+		Count:       n,
+		Flags:       Data | Var | Abs,
+		PhysicalMax: 315,
+		Unit:        0x14, // UNIT (Eng Rotation: Centimeter)
+	}
+	for range n {
+		g.Usages = append(g.Usages, UsageHatSwitch)
+	}
+	return g
+}
+
+// Size returns the size of the report in bytes, without the report id.
+func (l *Layout) Size() int {
+	return l.size / 8
+}
+
+// Items returns the report id and the main items of all groups, to be
+// placed inside the application collection. The physical range and unit
+// of hats are reset afterwards so that later items do not inherit them.
+func (l *Layout) Items() []byte {
+	items := ReportID(l.ReportID)
+	unit := false
+	for _, g := range l.Groups {
+		if g.Count == 0 {
+			continue
+		}
+		items = append(items, UsagePage(g.Page)...)
+		if g.Usages != nil {
+			for _, u := range g.Usages {
+				items = append(items, Usage(u)...)
+			}
+		} else {
+			items = Append(items, UsageMinimum(g.UsageMin), UsageMaximum(g.UsageMin+g.Count-1))
+		}
+		items = Append(items, LogicalMinimum(g.Min), LogicalMaximum(g.Max))
+		if g.Unit != 0 || unit {
+			items = Append(items, PhysicalMinimum(g.PhysicalMin), PhysicalMaximum(g.PhysicalMax), Unit(g.Unit))
+			unit = g.Unit != 0
+		}
+		items = Append(items, ReportSize(g.Size), ReportCount(g.Count), Input(g.Flags))
+		if pad := -(g.Size * g.Count) & 7; pad != 0 {
+			items = Append(items,
+				ReportSize(pad), // Padding
+				ReportCount(1),
+				Input(Const|Var|Abs),
+			)
+		}
+	}
+	if unit {
+		items = Append(items, PhysicalMinimum(0), PhysicalMaximum(0), Unit(0))
+	}
+	return items
+}
+
+// Put stores value i of the group in report, which must not include the
+// report id byte. v is truncated to the value size, not clamped.
+func (g *Group) Put(report []byte, i, v int) {
+	bit := g.offset + i*g.Size
+	for n := g.Size; n > 0; {
+		k, s := bit/8, bit%8
+		w := min(8-s, n)
+		mask := byte(1<<w-1) << s
+		report[k] = report[k]&^mask | byte(v<<s)&mask
+		v >>= w
+		bit += w
+		n -= w
+	}
+}
+
+// Get extracts value i of the group from report.
+func (g *Group) Get(report []byte, i int) int {
+	f := Field{Offset: g.offset + i*g.Size, Size: g.Size, signedValue: g.Min < 0}
+	return f.Value(report)
+}
@@ -0,0 +1,263 @@
+package hid
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Report kinds.
+const (
+	KindInput   = tagInput
+	KindOutput  = tagOutput
+	KindFeature = tagFeature
+)
+
+// Field is a single value in a report. Fields declared with a report count
+// are expanded into one Field per value.
+type Field struct {
+	Name        string
+	Kind        int
+	ReportID    int
+	Offset      int // bit offset, not counting the report id byte
+	Size        int // bits
+	UsagePage   int
+	Usage       int
+	LogicalMin  int
+	LogicalMax  int
+	Flags       int
+	Padding     bool
+	signedValue bool
+}
+
+// Descriptor is the field layout derived from a report descriptor.
+type Descriptor struct {
+	Fields []Field
+	sizes  map[[2]int]int // kind, report id -> bits
+}
+
+type globals struct {
+	usagePage  int
+	logicalMin int
+	logicalMax int
+	reportSize int
+	reportID   int
+	count      int
+}
+
+var ErrDescriptor = errors.New("hid: malformed report descriptor")
+
+// Parse derives the field layout of all reports declared in desc.
+func Parse(desc []byte) (*Descriptor, error) {
+	d := &Descriptor{sizes: map[[2]int]int{}}
+	var g globals
+	var stack []globals
+	var usages []int
+	usageMin, usageMax := -1, -1
+	names := map[int]map[string]int{} // per report id
+	depth := 0
+	for i := 0; i < len(desc); {
+		prefix := desc[i]
+		if prefix == 0xfe {
+			return nil, fmt.Errorf("%w: long items are not supported", ErrDescriptor)
+		}
+		n := int(prefix & 3)
+		if n == 3 {
+			n = 4
+		}
+		if i+1+n > len(desc) {
+			return nil, fmt.Errorf("%w: truncated item at %d", ErrDescriptor, i)
+		}
+		data := desc[i+1 : i+1+n]
+		i += 1 + n
+		u, v := unsignedData(data), signedData(data)
+		typ, tag := prefix>>2&3, prefix>>4
+		switch typ {
+		case typeMain:
+			switch tag {
+			case tagInput, tagOutput, tagFeature:
+				key := [2]int{int(tag), g.reportID}
+				for k := 0; k < g.count; k++ {
+					f := Field{
+						Kind:        int(tag),
+						ReportID:    g.reportID,
+						Offset:      d.sizes[key],
+						Size:        g.reportSize,
+						UsagePage:   g.usagePage,
+						LogicalMin:  g.logicalMin,
+						LogicalMax:  g.logicalMax,
+						Flags:       u,
+						signedValue: g.logicalMin < 0,
+					}
+					switch {
+					case len(usages) > 0:
+						f.Usage = usages[min(k, len(usages)-1)]
+					case usageMin >= 0:
+						f.Usage = min(usageMin+k, usageMax)
+					default:
+						f.Padding = true
+					}
+					if f.Usage > 0xffff {
+						f.UsagePage, f.Usage = f.Usage>>16, f.Usage&0xffff
+					}
+					if !f.Padding {
+						if names[g.reportID] == nil {
+							names[g.reportID] = map[string]int{}
+						}
+						f.Name = uniqueName(names[g.reportID], usageName(f.UsagePage, f.Usage))
+					}
+					d.Fields = append(d.Fields, f)
+					d.sizes[key] += g.reportSize
+				}
+			case tagCollection:
+				depth++
+			case tagEndCollection:
+				if depth--; depth < 0 {
+					return nil, fmt.Errorf("%w: unbalanced end collection", ErrDescriptor)
+				}
+			}
+			usages, usageMin, usageMax = nil, -1, -1
+		case typeGlobal:
+			switch tag {
+			case tagUsagePage:
+				g.usagePage = u
+			case tagLogicalMinimum:
+				g.logicalMin = v
+			case tagLogicalMaximum:
+				g.logicalMax = v
+				if g.logicalMin >= 0 {
+					g.logicalMax = u
+				}
+			case tagReportSize:
+				g.reportSize = u
+			case tagReportID:
+				g.reportID = u
+			case tagReportCount:
+				g.count = u
+			case tagPush:
+				stack = append(stack, g)
+			case tagPop:
+				if len(stack) == 0 {
+					return nil, fmt.Errorf("%w: pop without push", ErrDescriptor)
+				}
+				g, stack = stack[len(stack)-1], stack[:len(stack)-1]
+			}
+		case typeLocal:
+			if n < 4 {
+				// 4 byte usages carry their own page
+				u |= g.usagePage << 16
+			}
+			switch tag {
+			case tagUsage:
+				usages = append(usages, u)
+			case tagUsageMinimum:
+				usageMin = u
+			case tagUsageMaximum:
+				usageMax = u
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("%w: unterminated collection", ErrDescriptor)
+	}
+	return d, nil
+}
+
+// ReportSize returns the size in bytes of a report, without the report id.
+func (d *Descriptor) ReportSize(kind, reportID int) int {
+	return (d.sizes[[2]int{kind, reportID}] + 7) / 8
+}
+
+// Decode returns the named values of an input report. report must not
+// include the report id byte.
+func (d *Descriptor) Decode(reportID int, report []byte) (map[string]int, error) {
+	if n := d.ReportSize(KindInput, reportID); len(report) != n {
+		return nil, fmt.Errorf("hid: report %d is %d bytes, want %d", reportID, len(report), n)
+	}
+	values := map[string]int{}
+	for _, f := range d.Fields {
+		if f.Kind != KindInput || f.ReportID != reportID || f.Padding {
+			continue
+		}
+		values[f.Name] = f.Value(report)
+	}
+	return values, nil
+}
+
+// Value extracts the field from a report.
+func (f *Field) Value(report []byte) int {
+	v := 0
+	for i := 0; i < f.Size; i++ {
+		bit := f.Offset + i
+		if report[bit/8]&(1<<(bit%8)) != 0 {
+			v |= 1 << i
+		}
+	}
+	if f.signedValue && f.Size < 64 && v&(1<<(f.Size-1)) != 0 {
+		v -= 1 << f.Size
+	}
+	return v
+}
+
+func unsignedData(b []byte) int {
+	v := 0
+	for i, c := range b {
+		v |= int(c) << (8 * i)
+	}
+	return v
+}
+
+func signedData(b []byte) int {
+	switch len(b) {
+	case 1:
+		return int(int8(b[0]))
+	case 2:
+		return int(int16(uint16(b[0]) | uint16(b[1])<<8))
+	case 4:
+		return int(int32(uint32(unsignedData(b))))
+	}
+	return 0
+}
+
+var desktopNames = map[int]string{
+	UsagePointer:   "Pointer",
+	UsageMouse:     "Mouse",
+	UsageJoystick:  "Joystick",
+	UsageGamepad:   "Gamepad",
+	UsageKeyboard:  "Keyboard",
+	UsageX:         "X",
+	UsageY:         "Y",
+	UsageZ:         "Z",
+	UsageRx:        "Rx",
+	UsageRy:        "Ry",
+	UsageRz:        "Rz",
+	UsageSlider:    "Slider",
+	UsageDial:      "Dial",
+	UsageWheel:     "Wheel",
+	UsageHatSwitch: "Hat Switch",
+}
+
+func usageName(page, usage int) string {
+	switch page {
+	case PageGenericDesktop:
+		if name, ok := desktopNames[usage]; ok {
+			return name
+		}
+	case PageButton:
+		return "Button " + strconv.Itoa(usage)
+	case PageKeyboard:
+		return "Key " + strconv.Itoa(usage)
+	}
+	return fmt.Sprintf("Usage %02x:%02x", page, usage)
+}
+
+// uniqueName numbers usages repeated within a report, e.g. "Hat Switch",
+// "Hat Switch 2", so that reports of the same layout decode to the same
+// names.
+func uniqueName(names map[string]int, name string) string {
+	names[name]++
+	if n := names[name]; n > 1 {
+		return name + " " + strconv.Itoa(n)
+	}
+	return name
+}
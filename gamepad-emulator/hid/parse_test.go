@@ -0,0 +1,169 @@
+package hid
+
+import (
+	"errors"
+	"maps"
+	"testing"
+)
+
+func TestParseDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		desc   []byte
+		id     int
+		size   int
+		report []byte
+		want   map[string]int
+	}{
+		{
+			name: "signed axes",
+			desc: Append(
+				UsagePage(PageGenericDesktop), Usage(UsageGamepad), Collection(CollectionApplication),
+				ReportID(1), Usage(UsageX), Usage(UsageY),
+				LogicalMinimum(-32767), LogicalMaximum(32767), ReportSize(16), ReportCount(2), Input(Data|Var|Abs),
+				EndCollection(),
+			),
+			id:     1,
+			size:   4,
+			report: []byte{0xfe, 0xff, 0x2c, 0x01},
+			want:   map[string]int{"X": -2, "Y": 300},
+		},
+		{
+			name: "buttons and padding",
+			desc: Append(
+				UsagePage(PageGenericDesktop), Usage(UsageGamepad), Collection(CollectionApplication),
+				UsagePage(PageButton), UsageMinimum(1), UsageMaximum(3),
+				LogicalMinimum(0), LogicalMaximum(1), ReportSize(1), ReportCount(3), Input(Data|Var|Abs),
+				ReportSize(5), ReportCount(1), Input(Const|Var|Abs),
+				EndCollection(),
+			),
+			size:   1,
+			report: []byte{0xfd},
+			want:   map[string]int{"Button 1": 1, "Button 2": 0, "Button 3": 1},
+		},
+		{
+			name: "unsigned byte range",
+			desc: Append(
+				UsagePage(PageGenericDesktop), Usage(UsageJoystick), Collection(CollectionApplication),
+				Usage(UsageSlider), LogicalMinimum(0), LogicalMaximum(255), ReportSize(8), ReportCount(1), Input(Data|Var|Abs),
+				EndCollection(),
+			),
+			size:   1,
+			report: []byte{0xc8},
+			want:   map[string]int{"Slider": 200},
+		},
+		{
+			name: "push and pop restore globals",
+			desc: Append(
+				UsagePage(PageGenericDesktop), Usage(UsageGamepad), Collection(CollectionApplication),
+				LogicalMinimum(-128), LogicalMaximum(127), ReportSize(8), ReportCount(1),
+				Push(),
+				LogicalMinimum(0), LogicalMaximum(255),
+				Usage(UsageZ), Input(Data|Var|Abs),
+				Pop(),
+				Usage(UsageRz), Input(Data|Var|Abs),
+				EndCollection(),
+			),
+			size:   2,
+			report: []byte{0xff, 0xff},
+			want:   map[string]int{"Z": 255, "Rz": -1},
+		},
+		{
+			name: "names repeat per report",
+			desc: Append(
+				UsagePage(PageGenericDesktop), Usage(UsageGamepad), Collection(CollectionApplication),
+				LogicalMinimum(0), LogicalMaximum(7), ReportSize(4), ReportCount(2),
+				ReportID(1), Usage(UsageHatSwitch), Usage(UsageHatSwitch), Input(Data|Var|Abs),
+				ReportID(5), Usage(UsageHatSwitch), Usage(UsageHatSwitch), Input(Data|Var|Abs),
+				EndCollection(),
+			),
+			id:     5,
+			size:   1,
+			report: []byte{0x32},
+			want:   map[string]int{"Hat Switch": 2, "Hat Switch 2": 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Parse(tt.desc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := d.ReportSize(KindInput, tt.id); n != tt.size {
+				t.Errorf("ReportSize = %d, want %d", n, tt.size)
+			}
+			got, err := d.Decode(tt.id, tt.report)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("Decode = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		desc []byte
+	}{
+		{"truncated item", []byte{0x26, 0xff}},
+		{"unbalanced end", Append(EndCollection())},
+		{"unterminated collection", Append(Collection(CollectionApplication))},
+		{"pop without push", Append(Pop())},
+		{"long item", []byte{0xfe, 0x00, 0x00}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.desc); !errors.Is(err, ErrDescriptor) {
+				t.Errorf("Parse = %v, want ErrDescriptor", err)
+			}
+		})
+	}
+}
+
+func TestDecodeSize(t *testing.T) {
+	d, err := Parse(Append(
+		Collection(CollectionApplication),
+		ReportID(1), UsagePage(PageButton), UsageMinimum(1), UsageMaximum(8),
+		LogicalMaximum(1), ReportSize(1), ReportCount(8), Input(Data|Var|Abs),
+		EndCollection(),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Decode(1, []byte{0, 0}); err == nil {
+		t.Error("Decode accepted a report of the wrong size")
+	}
+}
+
+func TestLayoutRoundTrip(t *testing.T) {
+	axes := &Group{Page: PageGenericDesktop, Usages: []int{UsageX, UsageY}, Min: -32767, Max: 32767, Size: 16, Flags: Data | Var | Abs}
+	buttons := Buttons(3)
+	hats := Hats(1)
+	l := NewLayout(2, axes, buttons, hats)
+	d, err := Parse(Append(Collection(CollectionApplication), l.Items(), EndCollection()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := d.ReportSize(KindInput, 2); n != l.Size() {
+		t.Fatalf("descriptor size %d, layout size %d", n, l.Size())
+	}
+	report := make([]byte, l.Size())
+	axes.Put(report, 0, -1234)
+	axes.Put(report, 1, 32767)
+	buttons.Put(report, 2, 1)
+	hats.Put(report, 0, 6)
+	got, err := d.Decode(2, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"X": -1234, "Y": 32767, "Button 1": 0, "Button 2": 0, "Button 3": 1, "Hat Switch": 6}
+	if !maps.Equal(got, want) {
+		t.Errorf("Decode = %v, want %v", got, want)
+	}
+	if v := axes.Get(report, 0); v != -1234 {
+		t.Errorf("Get = %d, want -1234", v)
+	}
+}
@@ -0,0 +1,255 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/mailru/easyjson"
+)
+
+var ErrTimeout = errors.New("jsonrpc: call timed out")
+
+// Call is one entry of a batch. Calls with Notify set are sent as
+// notifications and get no Result or Err.
+type Call struct {
+	Method string
+	Params map[string]any
+	Notify bool
+	Result easyjson.RawMessage
+	Err    error
+}
+
+// Client speaks newline delimited JSON-RPC over a byte stream. A reader
+// goroutine matches replies to calls by id and hands notifications sent by
+// the peer to the handlers registered with HandleNotification.
+type Client struct {
+	Timeout time.Duration
+
+	w       io.Writer
+	mu      sync.Mutex
+	id      int64
+	pending map[ID]chan *Response
+	notify  map[string]func(params map[string]any)
+	err     error
+}
+
+func NewClient(rw io.ReadWriter) *Client {
+	c := &Client{
+		Timeout: 3 * time.Second,
+		w:       rw,
+		pending: map[ID]chan *Response{},
+		notify:  map[string]func(map[string]any){},
+	}
+	go c.readLoop(bufio.NewReader(rw))
+	return c
+}
+
+// HandleNotification sets the handler for notifications of method from the
+// peer, replacing any previous one. Handlers run on the reader goroutine;
+// notifications without a handler are dropped.
+func (c *Client) HandleNotification(method string, f func(params map[string]any)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notify[method] = f
+}
+
+func (c *Client) Call(method string, params map[string]any) (easyjson.RawMessage, error) {
+	call := &Call{Method: method, Params: params}
+	c.mu.Lock()
+	req, ch := c.register(call)
+	c.mu.Unlock()
+	if err := c.write(req); err != nil {
+		c.cancel(req.ID)
+		return nil, err
+	}
+	c.wait(call, req.ID, ch, time.After(c.Timeout))
+	return call.Result, call.Err
+}
+
+func (c *Client) Notify(method string, params map[string]any) error {
+	return c.write(&Request{
+		JsonRpc: Version,
+		Method:  method,
+		Params:  params,
+	})
+}
+
+// Batch sends all calls in a single write and waits for the replies of the
+// non-notification calls.
+func (c *Client) Batch(calls ...*Call) error {
+	batch := make(Batch, 0, len(calls))
+	chans := make([]chan *Response, len(calls))
+	c.mu.Lock()
+	for i, call := range calls {
+		req, ch := c.register(call)
+		chans[i] = ch
+		batch = append(batch, *req)
+	}
+	c.mu.Unlock()
+	if err := c.write(batch); err != nil {
+		for _, req := range batch {
+			c.cancel(req.ID)
+		}
+		return err
+	}
+	timeout := time.After(c.Timeout)
+	for i, call := range calls {
+		if chans[i] != nil {
+			c.wait(call, batch[i].ID, chans[i], timeout)
+		}
+	}
+	for _, call := range calls {
+		if call.Err != nil {
+			return call.Err
+		}
+	}
+	return nil
+}
+
+// register builds the request for call and, unless it is a notification,
+// a channel for its reply. c.mu must be held.
+func (c *Client) register(call *Call) (*Request, chan *Response) {
+	req := &Request{
+		JsonRpc: Version,
+		Method:  call.Method,
+		Params:  call.Params,
+	}
+	if call.Notify {
+		return req, nil
+	}
+	c.id++
+	req.ID = IntID(c.id)
+	ch := make(chan *Response, 1)
+	c.pending[req.ID] = ch
+	return req, ch
+}
+
+func (c *Client) cancel(id ID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pending, id)
+}
+
+func (c *Client) wait(call *Call, id ID, ch chan *Response, timeout <-chan time.Time) {
+	select {
+	case resp, ok := <-ch:
+		switch {
+		case !ok:
+			call.Err = c.readErr()
+		case resp.Error != nil:
+			call.Err = resp.Error
+		default:
+			call.Result = resp.Result
+		}
+	case <-timeout:
+		c.cancel(id)
+		call.Err = fmt.Errorf("%w: %s", ErrTimeout, call.Method)
+	}
+}
+
+func (c *Client) readErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *Client) write(v easyjson.Marshaler) error {
+	b, err := easyjson.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	_, err = c.w.Write(append(b, '\n'))
+	return err
+}
+
+// WriteRaw writes b as is, serialized with the client's own messages so
+// that other framings can share the stream.
+func (c *Client) WriteRaw(b []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	_, err := c.w.Write(b)
+	return err
+}
+
+func (c *Client) readLoop(r *bufio.Reader) {
+	var buf []byte
+	for {
+		line, err := r.ReadBytes('\n')
+		buf = append(buf, line...)
+		if errors.Is(err, io.ErrNoProgress) {
+			// read timeouts without data; keep the partial line
+			continue
+		}
+		if msg := bytes.TrimSpace(buf); err == nil && len(msg) > 0 {
+			c.dispatch(msg)
+		}
+		buf = nil
+		if err != nil {
+			c.mu.Lock()
+			c.err = fmt.Errorf("jsonrpc: connection lost: %w", err)
+			for id, ch := range c.pending {
+				close(ch)
+				delete(c.pending, id)
+			}
+			c.mu.Unlock()
+			return
+		}
+	}
+}
+
+func (c *Client) dispatch(msg []byte) {
+	var resps BatchResponse
+	if msg[0] == '[' {
+		if err := resps.UnmarshalJSON(msg); err != nil {
+			return
+		}
+	} else {
+		var m message
+		if err := m.UnmarshalJSON(msg); err != nil {
+			return
+		}
+		if m.Method != "" {
+			// requests from the peer are not served; only notifications
+			if !m.ID.IsDefined() {
+				c.mu.Lock()
+				notify := c.notify[m.Method]
+				c.mu.Unlock()
+				if notify != nil {
+					notify(m.Params)
+				}
+			}
+			return
+		}
+		resps = BatchResponse{{ID: m.ID, JsonRpc: Version, Result: m.Result, Error: m.Error}}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range resps {
+		resp := &resps[i]
+		if !resp.ID.IsDefined() || resp.ID == NullID {
+			// the peer could not tell which request failed
+			for id, ch := range c.pending {
+				ch <- resp
+				delete(c.pending, id)
+			}
+			continue
+		}
+		if ch, ok := c.pending[resp.ID]; ok {
+			ch <- resp
+			delete(c.pending, resp.ID)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"io"
+	"testing"
+)
+
+type pipeConn struct {
+	io.Reader
+	io.Writer
+}
+
+func TestClientDispatch(t *testing.T) {
+	clientR, peerW := io.Pipe()
+	peerR, clientW := io.Pipe()
+	c := NewClient(pipeConn{Reader: clientR, Writer: clientW})
+	notified := make(chan map[string]any, 1)
+	c.HandleNotification("Switch", func(params map[string]any) {
+		notified <- params
+	})
+	go func() {
+		r := bufio.NewReader(peerR)
+		if _, err := r.ReadBytes('\n'); err != nil {
+			return
+		}
+		io.WriteString(peerW, `{"jsonrpc":"2.0","method":"Switch","params":{"index":1}}`+"\n")
+		io.WriteString(peerW, `{"id":1,"jsonrpc":"2.0","result":"has \"method\" inside"}`+"\n")
+		if _, err := r.ReadBytes('\n'); err != nil {
+			return
+		}
+		io.WriteString(peerW, `{"id":2,"jsonrpc":"2.0","error":{"code":-32601,"message":"\"method\" not found"}}`+"\n")
+	}()
+	res, err := c.Call("Echo", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(res), `"has \"method\" inside"`; got != want {
+		t.Errorf("result = %s, want %s", got, want)
+	}
+	if params := <-notified; params["index"] != 1.0 {
+		t.Errorf("notification params = %v", params)
+	}
+	_, err = c.Call("Missing", nil)
+	if e, ok := err.(*Error); !ok || e.Code != CodeMethodNotFound {
+		t.Errorf("error = %v, want method not found", err)
+	}
+}
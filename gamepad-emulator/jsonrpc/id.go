@@ -0,0 +1,87 @@
+package jsonrpc
+
+import (
+	"strconv"
+
+	"github.com/mailru/easyjson/jlexer"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+type idKind uint8
+
+const (
+	idNone idKind = iota
+	idNull
+	idNumber
+	idString
+)
+
+// ID is a request id as defined by JSON-RPC 2.0: a number, a string or
+// null. The zero value is an absent id, which marks a notification.
+//
+//easyjson:skip
+type ID struct {
+	kind idKind
+	num  int64
+	str  string
+}
+
+var NullID = ID{kind: idNull}
+
+func IntID(n int64) ID {
+	return ID{kind: idNumber, num: n}
+}
+
+func StringID(s string) ID {
+	return ID{kind: idString, str: s}
+}
+
+// IsDefined reports whether the id was present, including an explicit null.
+func (id ID) IsDefined() bool {
+	return id.kind != idNone
+}
+
+func (id ID) String() string {
+	switch id.kind {
+	case idNumber:
+		return strconv.FormatInt(id.num, 10)
+	case idString:
+		return strconv.Quote(id.str)
+	}
+	return "null"
+}
+
+func (id ID) MarshalEasyJSON(w *jwriter.Writer) {
+	switch id.kind {
+	case idNumber:
+		w.Int64(id.num)
+	case idString:
+		w.String(id.str)
+	default:
+		w.RawString("null")
+	}
+}
+
+func (id *ID) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	switch {
+	case l.IsNull():
+		l.Skip()
+		*id = NullID
+	case l.CurrentToken() == jlexer.TokenString:
+		*id = StringID(l.String())
+	default:
+		*id = IntID(l.Int64())
+	}
+}
+
+func (id ID) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	id.MarshalEasyJSON(&w)
+	return w.BuildBytes()
+}
+
+func (id *ID) UnmarshalJSON(data []byte) error {
+	l := jlexer.Lexer{Data: data}
+	id.UnmarshalEasyJSON(&l)
+	return l.Error()
+}
@@ -1,21 +1,67 @@
 package jsonrpc
 
+import "github.com/mailru/easyjson"
+
 //go:generate easyjson -all jsonrpc.go
 
+const Version = "2.0"
+
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Request is encoded by hand in request.go so that an explicit null id can
+// be told apart from a missing one.
+//
+//easyjson:skip
 type Request struct {
-	ID      int            `json:"id"`
+	ID      ID             `json:"id,omitempty"`
 	JsonRpc string         `json:"jsonrpc"`
 	Method  string         `json:"method"`
 	Params  map[string]any `json:"params,omitempty"`
 }
 
+// IsNotification reports whether the request has no id and expects no reply.
+func (r *Request) IsNotification() bool {
+	return !r.ID.IsDefined()
+}
+
 type Error struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
 }
 
 type Response struct {
-	ID     int `json:"id"`
-	Result any `json:"result,omitempty"`
-	Error  any `json:"error,omitempty"`
+	ID      ID                  `json:"id"`
+	JsonRpc string              `json:"jsonrpc"`
+	Result  easyjson.RawMessage `json:"result,omitempty"`
+	Error   *Error              `json:"error,omitempty"`
+}
+
+// message is a single message read by the client: a notification from the
+// peer has a method and no id, a response has an id and no method.
+type message struct {
+	ID     ID                  `json:"id"`
+	Method string              `json:"method"`
+	Params map[string]any      `json:"params"`
+	Result easyjson.RawMessage `json:"result"`
+	Error  *Error              `json:"error"`
 }
+
+//easyjson:json
+type Batch []Request
+
+//easyjson:json
+type BatchResponse []Response
+
+//easyjson:json
+type rawBatch []easyjson.RawMessage
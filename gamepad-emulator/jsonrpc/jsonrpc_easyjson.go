@@ -17,7 +17,77 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjsonE7d0658dDecodeGithubComNobonoboJsEmulatorJsonrpc(in *jlexer.Lexer, out *Response) {
+func easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc(in *jlexer.Lexer, out *rawBatch) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(rawBatch, 0, 2)
+			} else {
+				*out = rawBatch{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 easyjson.RawMessage
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				(v1).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc(out *jwriter.Writer, in rawBatch) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			(v3).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v rawBatch) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v rawBatch) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *rawBatch) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *rawBatch) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc(l, v)
+}
+func easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc1(in *jlexer.Lexer, out *message) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -35,23 +105,55 @@ func easyjsonE7d0658dDecodeGithubComNobonoboJsEmulatorJsonrpc(in *jlexer.Lexer,
 			if in.IsNull() {
 				in.Skip()
 			} else {
-				out.ID = int(in.Int())
+				(out.ID).UnmarshalEasyJSON(in)
+			}
+		case "method":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Method = string(in.String())
+			}
+		case "params":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.Params = make(map[string]interface{})
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v4 interface{}
+					if m, ok := v4.(easyjson.Unmarshaler); ok {
+						m.UnmarshalEasyJSON(in)
+					} else if m, ok := v4.(json.Unmarshaler); ok {
+						_ = m.UnmarshalJSON(in.Raw())
+					} else {
+						v4 = in.Interface()
+					}
+					(out.Params)[key] = v4
+					in.WantComma()
+				}
+				in.Delim('}')
 			}
 		case "result":
-			if m, ok := out.Result.(easyjson.Unmarshaler); ok {
-				m.UnmarshalEasyJSON(in)
-			} else if m, ok := out.Result.(json.Unmarshaler); ok {
-				_ = m.UnmarshalJSON(in.Raw())
+			if in.IsNull() {
+				in.Skip()
 			} else {
-				out.Result = in.Interface()
+				(out.Result).UnmarshalEasyJSON(in)
 			}
 		case "error":
-			if m, ok := out.Error.(easyjson.Unmarshaler); ok {
-				m.UnmarshalEasyJSON(in)
-			} else if m, ok := out.Error.(json.Unmarshaler); ok {
-				_ = m.UnmarshalJSON(in.Raw())
+			if in.IsNull() {
+				in.Skip()
+				out.Error = nil
 			} else {
-				out.Error = in.Interface()
+				if out.Error == nil {
+					out.Error = new(Error)
+				}
+				if in.IsNull() {
+					in.Skip()
+				} else {
+					(*out.Error).UnmarshalEasyJSON(in)
+				}
 			}
 		default:
 			in.SkipRecursive()
@@ -63,64 +165,88 @@ func easyjsonE7d0658dDecodeGithubComNobonoboJsEmulatorJsonrpc(in *jlexer.Lexer,
 		in.Consumed()
 	}
 }
-func easyjsonE7d0658dEncodeGithubComNobonoboJsEmulatorJsonrpc(out *jwriter.Writer, in Response) {
+func easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc1(out *jwriter.Writer, in message) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
 		const prefix string = ",\"id\":"
 		out.RawString(prefix[1:])
-		out.Int(int(in.ID))
+		(in.ID).MarshalEasyJSON(out)
 	}
-	if in.Result != nil {
-		const prefix string = ",\"result\":"
+	{
+		const prefix string = ",\"method\":"
 		out.RawString(prefix)
-		if m, ok := in.Result.(easyjson.Marshaler); ok {
-			m.MarshalEasyJSON(out)
-		} else if m, ok := in.Result.(json.Marshaler); ok {
-			out.Raw(m.MarshalJSON())
+		out.String(string(in.Method))
+	}
+	{
+		const prefix string = ",\"params\":"
+		out.RawString(prefix)
+		if in.Params == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
 		} else {
-			out.Raw(json.Marshal(in.Result))
+			out.RawByte('{')
+			v5First := true
+			for v5Name, v5Value := range in.Params {
+				if v5First {
+					v5First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v5Name))
+				out.RawByte(':')
+				if m, ok := v5Value.(easyjson.Marshaler); ok {
+					m.MarshalEasyJSON(out)
+				} else if m, ok := v5Value.(json.Marshaler); ok {
+					out.Raw(m.MarshalJSON())
+				} else {
+					out.Raw(json.Marshal(v5Value))
+				}
+			}
+			out.RawByte('}')
 		}
 	}
-	if in.Error != nil {
+	{
+		const prefix string = ",\"result\":"
+		out.RawString(prefix)
+		(in.Result).MarshalEasyJSON(out)
+	}
+	{
 		const prefix string = ",\"error\":"
 		out.RawString(prefix)
-		if m, ok := in.Error.(easyjson.Marshaler); ok {
-			m.MarshalEasyJSON(out)
-		} else if m, ok := in.Error.(json.Marshaler); ok {
-			out.Raw(m.MarshalJSON())
+		if in.Error == nil {
+			out.RawString("null")
 		} else {
-			out.Raw(json.Marshal(in.Error))
+			(*in.Error).MarshalEasyJSON(out)
 		}
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Response) MarshalJSON() ([]byte, error) {
+func (v message) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonE7d0658dEncodeGithubComNobonoboJsEmulatorJsonrpc(&w, v)
+	easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc1(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Response) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonE7d0658dEncodeGithubComNobonoboJsEmulatorJsonrpc(w, v)
+func (v message) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc1(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Response) UnmarshalJSON(data []byte) error {
+func (v *message) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonE7d0658dDecodeGithubComNobonoboJsEmulatorJsonrpc(&r, v)
+	easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc1(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Response) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonE7d0658dDecodeGithubComNobonoboJsEmulatorJsonrpc(l, v)
+func (v *message) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc1(l, v)
 }
-func easyjsonE7d0658dDecodeGithubComNobonoboJsEmulatorJsonrpc1(in *jlexer.Lexer, out *Request) {
+func easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc2(in *jlexer.Lexer, out *Response) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -138,7 +264,7 @@ func easyjsonE7d0658dDecodeGithubComNobonoboJsEmulatorJsonrpc1(in *jlexer.Lexer,
 			if in.IsNull() {
 				in.Skip()
 			} else {
-				out.ID = int(in.Int())
+				(out.ID).UnmarshalEasyJSON(in)
 			}
 		case "jsonrpc":
 			if in.IsNull() {
@@ -146,37 +272,25 @@ func easyjsonE7d0658dDecodeGithubComNobonoboJsEmulatorJsonrpc1(in *jlexer.Lexer,
 			} else {
 				out.JsonRpc = string(in.String())
 			}
-		case "method":
+		case "result":
 			if in.IsNull() {
 				in.Skip()
 			} else {
-				out.Method = string(in.String())
+				(out.Result).UnmarshalEasyJSON(in)
 			}
-		case "params":
+		case "error":
 			if in.IsNull() {
 				in.Skip()
+				out.Error = nil
 			} else {
-				in.Delim('{')
-				if !in.IsDelim('}') {
-					out.Params = make(map[string]interface{})
-				} else {
-					out.Params = nil
+				if out.Error == nil {
+					out.Error = new(Error)
 				}
-				for !in.IsDelim('}') {
-					key := string(in.String())
-					in.WantColon()
-					var v1 interface{}
-					if m, ok := v1.(easyjson.Unmarshaler); ok {
-						m.UnmarshalEasyJSON(in)
-					} else if m, ok := v1.(json.Unmarshaler); ok {
-						_ = m.UnmarshalJSON(in.Raw())
-					} else {
-						v1 = in.Interface()
-					}
-					(out.Params)[key] = v1
-					in.WantComma()
+				if in.IsNull() {
+					in.Skip()
+				} else {
+					(*out.Error).UnmarshalEasyJSON(in)
 				}
-				in.Delim('}')
 			}
 		default:
 			in.SkipRecursive()
@@ -188,77 +302,57 @@ func easyjsonE7d0658dDecodeGithubComNobonoboJsEmulatorJsonrpc1(in *jlexer.Lexer,
 		in.Consumed()
 	}
 }
-func easyjsonE7d0658dEncodeGithubComNobonoboJsEmulatorJsonrpc1(out *jwriter.Writer, in Request) {
+func easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc2(out *jwriter.Writer, in Response) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
 		const prefix string = ",\"id\":"
 		out.RawString(prefix[1:])
-		out.Int(int(in.ID))
+		(in.ID).MarshalEasyJSON(out)
 	}
 	{
 		const prefix string = ",\"jsonrpc\":"
 		out.RawString(prefix)
 		out.String(string(in.JsonRpc))
 	}
-	{
-		const prefix string = ",\"method\":"
+	if (in.Result).IsDefined() {
+		const prefix string = ",\"result\":"
 		out.RawString(prefix)
-		out.String(string(in.Method))
+		(in.Result).MarshalEasyJSON(out)
 	}
-	if len(in.Params) != 0 {
-		const prefix string = ",\"params\":"
+	if in.Error != nil {
+		const prefix string = ",\"error\":"
 		out.RawString(prefix)
-		{
-			out.RawByte('{')
-			v2First := true
-			for v2Name, v2Value := range in.Params {
-				if v2First {
-					v2First = false
-				} else {
-					out.RawByte(',')
-				}
-				out.String(string(v2Name))
-				out.RawByte(':')
-				if m, ok := v2Value.(easyjson.Marshaler); ok {
-					m.MarshalEasyJSON(out)
-				} else if m, ok := v2Value.(json.Marshaler); ok {
-					out.Raw(m.MarshalJSON())
-				} else {
-					out.Raw(json.Marshal(v2Value))
-				}
-			}
-			out.RawByte('}')
-		}
+		(*in.Error).MarshalEasyJSON(out)
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Request) MarshalJSON() ([]byte, error) {
+func (v Response) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonE7d0658dEncodeGithubComNobonoboJsEmulatorJsonrpc1(&w, v)
+	easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc2(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Request) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonE7d0658dEncodeGithubComNobonoboJsEmulatorJsonrpc1(w, v)
+func (v Response) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc2(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Request) UnmarshalJSON(data []byte) error {
+func (v *Response) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonE7d0658dDecodeGithubComNobonoboJsEmulatorJsonrpc1(&r, v)
+	easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc2(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Request) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonE7d0658dDecodeGithubComNobonoboJsEmulatorJsonrpc1(l, v)
+func (v *Response) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc2(l, v)
 }
-func easyjsonE7d0658dDecodeGithubComNobonoboJsEmulatorJsonrpc2(in *jlexer.Lexer, out *Error) {
+func easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc3(in *jlexer.Lexer, out *Error) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -284,6 +378,14 @@ func easyjsonE7d0658dDecodeGithubComNobonoboJsEmulatorJsonrpc2(in *jlexer.Lexer,
 			} else {
 				out.Message = string(in.String())
 			}
+		case "data":
+			if m, ok := out.Data.(easyjson.Unmarshaler); ok {
+				m.UnmarshalEasyJSON(in)
+			} else if m, ok := out.Data.(json.Unmarshaler); ok {
+				_ = m.UnmarshalJSON(in.Raw())
+			} else {
+				out.Data = in.Interface()
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -294,7 +396,7 @@ func easyjsonE7d0658dDecodeGithubComNobonoboJsEmulatorJsonrpc2(in *jlexer.Lexer,
 		in.Consumed()
 	}
 }
-func easyjsonE7d0658dEncodeGithubComNobonoboJsEmulatorJsonrpc2(out *jwriter.Writer, in Error) {
+func easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc3(out *jwriter.Writer, in Error) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -308,29 +410,180 @@ func easyjsonE7d0658dEncodeGithubComNobonoboJsEmulatorJsonrpc2(out *jwriter.Writ
 		out.RawString(prefix)
 		out.String(string(in.Message))
 	}
+	if in.Data != nil {
+		const prefix string = ",\"data\":"
+		out.RawString(prefix)
+		if m, ok := in.Data.(easyjson.Marshaler); ok {
+			m.MarshalEasyJSON(out)
+		} else if m, ok := in.Data.(json.Marshaler); ok {
+			out.Raw(m.MarshalJSON())
+		} else {
+			out.Raw(json.Marshal(in.Data))
+		}
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v Error) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonE7d0658dEncodeGithubComNobonoboJsEmulatorJsonrpc2(&w, v)
+	easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc3(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Error) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonE7d0658dEncodeGithubComNobonoboJsEmulatorJsonrpc2(w, v)
+	easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc3(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Error) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonE7d0658dDecodeGithubComNobonoboJsEmulatorJsonrpc2(&r, v)
+	easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc3(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Error) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonE7d0658dDecodeGithubComNobonoboJsEmulatorJsonrpc2(l, v)
+	easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc3(l, v)
+}
+func easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc4(in *jlexer.Lexer, out *BatchResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(BatchResponse, 0, 0)
+			} else {
+				*out = BatchResponse{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v6 Response
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				(v6).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v6)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc4(out *jwriter.Writer, in BatchResponse) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v7, v8 := range in {
+			if v7 > 0 {
+				out.RawByte(',')
+			}
+			(v8).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v BatchResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v BatchResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *BatchResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *BatchResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc4(l, v)
+}
+func easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc5(in *jlexer.Lexer, out *Batch) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(Batch, 0, 0)
+			} else {
+				*out = Batch{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v9 Request
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				(v9).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v9)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc5(out *jwriter.Writer, in Batch) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v10, v11 := range in {
+			if v10 > 0 {
+				out.RawByte(',')
+			}
+			(v11).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Batch) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc5(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Batch) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE7d0658dEncodeGithubComNobonoboGamepadEmulatorJsonrpc5(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Batch) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc5(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Batch) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE7d0658dDecodeGithubComNobonoboGamepadEmulatorJsonrpc5(l, v)
 }
@@ -0,0 +1,93 @@
+package jsonrpc
+
+import (
+	"github.com/mailru/easyjson/jlexer"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+func (v Request) MarshalEasyJSON(w *jwriter.Writer) {
+	w.RawByte('{')
+	if v.ID.IsDefined() {
+		w.RawString(`"id":`)
+		v.ID.MarshalEasyJSON(w)
+		w.RawByte(',')
+	}
+	w.RawString(`"jsonrpc":`)
+	w.String(v.JsonRpc)
+	w.RawString(`,"method":`)
+	w.String(v.Method)
+	if len(v.Params) > 0 {
+		w.RawString(`,"params":{`)
+		first := true
+		for key, value := range v.Params {
+			if !first {
+				w.RawByte(',')
+			}
+			first = false
+			w.String(key)
+			w.RawByte(':')
+			w.Raw(marshalValue(value))
+		}
+		w.RawByte('}')
+	}
+	w.RawByte('}')
+}
+
+func (v *Request) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	isTopLevel := l.IsStart()
+	if l.IsNull() {
+		if isTopLevel {
+			l.Consumed()
+		}
+		l.Skip()
+		return
+	}
+	l.Delim('{')
+	for !l.IsDelim('}') {
+		key := l.UnsafeFieldName(false)
+		l.WantColon()
+		switch key {
+		case "id":
+			v.ID.UnmarshalEasyJSON(l)
+		case "jsonrpc":
+			v.JsonRpc = l.String()
+		case "method":
+			v.Method = l.String()
+		case "params":
+			if !l.IsDelim('{') {
+				// by-position params are not supported; handlers will
+				// report the missing arguments.
+				l.SkipRecursive()
+				break
+			}
+			v.Params = map[string]any{}
+			l.Delim('{')
+			for !l.IsDelim('}') {
+				key := l.String()
+				l.WantColon()
+				v.Params[key] = l.Interface()
+				l.WantComma()
+			}
+			l.Delim('}')
+		default:
+			l.SkipRecursive()
+		}
+		l.WantComma()
+	}
+	l.Delim('}')
+	if isTopLevel {
+		l.Consumed()
+	}
+}
+
+func (v Request) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	v.MarshalEasyJSON(&w)
+	return w.BuildBytes()
+}
+
+func (v *Request) UnmarshalJSON(data []byte) error {
+	l := jlexer.Lexer{Data: data}
+	v.UnmarshalEasyJSON(&l)
+	return l.Error()
+}
@@ -0,0 +1,125 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/mailru/easyjson"
+)
+
+type Handler func(params map[string]any) (any, error)
+
+type Server struct {
+	methods map[string]Handler
+}
+
+func NewServer(methods map[string]Handler) *Server {
+	return &Server{methods: methods}
+}
+
+func (s *Server) Methods() []string {
+	names := make([]string, 0, len(s.methods))
+	for name := range s.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Call dispatches req and returns the reply, or nil for a notification.
+// Handlers may return an *Error to choose the error code.
+func (s *Server) Call(req *Request) *Response {
+	var result any
+	var err error
+	if h, ok := s.methods[req.Method]; ok {
+		result, err = h(req.Params)
+	} else {
+		err = &Error{Code: CodeMethodNotFound, Message: "method not found: " + req.Method}
+	}
+	if req.IsNotification() {
+		return nil
+	}
+	resp := &Response{ID: req.ID, JsonRpc: Version}
+	if err == nil {
+		resp.Result, err = marshalValue(result)
+	}
+	if err != nil {
+		resp.Error = toError(err)
+		resp.Result = nil
+	}
+	return resp
+}
+
+// Handle processes one message, a request object or a batch array, and
+// returns the encoded reply or nil when nothing has to be sent. Malformed
+// messages are answered with parse or invalid request errors.
+func (s *Server) Handle(msg []byte) []byte {
+	if !json.Valid(msg) {
+		return encode(NewErrorResponse(NullID, CodeParseError, "parse error"))
+	}
+	if msg[0] != '[' {
+		resp := s.handleRaw(msg)
+		if resp == nil {
+			return nil
+		}
+		return encode(resp)
+	}
+	var batch rawBatch
+	if err := batch.UnmarshalJSON(msg); err != nil || len(batch) == 0 {
+		return encode(NewErrorResponse(NullID, CodeInvalidRequest, "invalid request: empty batch"))
+	}
+	resps := BatchResponse{}
+	for _, raw := range batch {
+		if resp := s.handleRaw(raw); resp != nil {
+			resps = append(resps, *resp)
+		}
+	}
+	if len(resps) == 0 {
+		return nil
+	}
+	return encode(resps)
+}
+
+func (s *Server) handleRaw(raw []byte) *Response {
+	req := new(Request)
+	if err := req.UnmarshalJSON(raw); err != nil || req.JsonRpc != Version || req.Method == "" {
+		id := req.ID
+		if !id.IsDefined() {
+			id = NullID
+		}
+		return NewErrorResponse(id, CodeInvalidRequest, "invalid request")
+	}
+	return s.Call(req)
+}
+
+func NewErrorResponse(id ID, code int, message string) *Response {
+	return &Response{
+		ID:      id,
+		JsonRpc: Version,
+		Error:   &Error{Code: code, Message: message},
+	}
+}
+
+func encode(v easyjson.Marshaler) []byte {
+	b, err := easyjson.Marshal(v)
+	if err != nil {
+		b, _ = easyjson.Marshal(NewErrorResponse(NullID, CodeInternalError, err.Error()))
+	}
+	return b
+}
+
+func toError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return &Error{Code: CodeInternalError, Message: err.Error()}
+}
+
+func marshalValue(v any) ([]byte, error) {
+	if m, ok := v.(easyjson.Marshaler); ok {
+		return easyjson.Marshal(m)
+	}
+	return json.Marshal(v)
+}
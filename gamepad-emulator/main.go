@@ -1,3 +1,5 @@
+//go:build tinygo
+
 package main
 
 import (
@@ -5,8 +7,10 @@ import (
 	"log"
 	"os"
 
+	"device/rp"
 	"machine"
 
+	"github.com/nobonobo/gamepad-emulator/protocol"
 	"github.com/nobonobo/gamepad-emulator/service"
 )
 
@@ -24,21 +28,49 @@ type w struct {
 	io.WriteCloser
 }
 
+// store and pads are set up in init so that the USB identity and the HID
+// interface are in place before the USB device is configured.
+var (
+	store *service.SettingsStore
+	pads  []service.JoySticker
+)
+
+// usbBus reports the suspend state of the RP2040 USB controller.
+type usbBus struct{}
+
+func (usbBus) Suspended() bool {
+	return rp.USBCTRL_REGS.SIE_STATUS.HasBits(rp.USBCTRL_REGS_SIE_STATUS_SUSPENDED)
+}
+
 func init() {
 	LED1.Configure(machine.PinConfig{Mode: machine.PinOutput})
 	LED2.Configure(machine.PinConfig{Mode: machine.PinOutput})
 	LED3.Configure(machine.PinConfig{Mode: machine.PinOutput})
-	LED1.High()
-	LED2.High()
-	LED3.High()
 	SW1.Configure(machine.PinConfig{Mode: machine.PinInput})
 	SW2.Configure(machine.PinConfig{Mode: machine.PinInput})
 	SW3.Configure(machine.PinConfig{Mode: machine.PinInput})
+	store = service.NewSettingsStore(machine.Flash)
+	var err error
+	pads, err = service.NewUSB(service.BootSettings(store))
+	if err != nil {
+		log.Fatal(err)
+	}
 }
 
 func main() {
 	log.SetFlags(log.Lmicroseconds)
-	srv := service.New()
+	srv := service.New(pads...)
+	if err := srv.UseSettings(store); err != nil {
+		log.Println(err)
+	}
+	srv.WatchLine(machine.Serial)
+	srv.WatchUSB(usbBus{})
+	srv.AddSwitch(SW1, true)
+	srv.AddSwitch(SW2, true)
+	srv.AddSwitch(SW3, true)
+	srv.AddLED(LED1, protocol.LEDHeartbeat)
+	srv.AddLED(LED2, protocol.LEDActivity)
+	srv.AddLED(LED3, protocol.LEDWatchdog)
 	if err := srv.Run(w{Reader: os.Stdin, WriteCloser: os.Stdout}); err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,43 @@
+package protocol
+
+import "errors"
+
+var ErrCOBS = errors.New("cobs: malformed data")
+
+// COBSEncode appends the COBS encoding of src to dst. The result contains
+// no zero bytes.
+func COBSEncode(dst, src []byte) []byte {
+	code := len(dst)
+	dst = append(dst, 0)
+	n := byte(1)
+	for _, b := range src {
+		if b != 0 {
+			dst = append(dst, b)
+			n++
+		}
+		if b == 0 || n == 0xff {
+			dst[code] = n
+			code = len(dst)
+			dst = append(dst, 0)
+			n = 1
+		}
+	}
+	dst[code] = n
+	return dst
+}
+
+// COBSDecode appends the decoding of src to dst.
+func COBSDecode(dst, src []byte) ([]byte, error) {
+	for i := 0; i < len(src); {
+		n := int(src[i])
+		if n == 0 || i+n > len(src) {
+			return dst, ErrCOBS
+		}
+		dst = append(dst, src[i+1:i+n]...)
+		i += n
+		if n < 0xff && i < len(src) {
+			dst = append(dst, 0)
+		}
+	}
+	return dst, nil
+}
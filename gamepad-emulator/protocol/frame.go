@@ -0,0 +1,152 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+// Binary frames share the serial link with JSON-RPC lines. A frame is
+//
+//	0x00 COBS(type seq payload crc32) 0x00
+//
+// where crc32 (IEEE, little endian) covers type, seq and payload. The pad
+// frame types prefix the state with the index of the pad it is for.
+const (
+	FrameDelimiter = 0x00
+
+	FrameSetState        byte = 0x01
+	FrameSetStateSend    byte = 0x02
+	FrameSetPadState     byte = 0x03
+	FrameSetPadStateSend byte = 0x04
+)
+
+var (
+	ErrFrameShort = errors.New("frame: too short")
+	ErrFrameCRC   = errors.New("frame: crc mismatch")
+)
+
+type Frame struct {
+	Type    byte
+	Seq     uint8
+	Payload []byte
+}
+
+func AppendFrame(dst []byte, typ byte, seq uint8, payload []byte) []byte {
+	raw := make([]byte, 0, len(payload)+6)
+	raw = append(raw, typ, seq)
+	raw = append(raw, payload...)
+	raw = binary.LittleEndian.AppendUint32(raw, crc32.ChecksumIEEE(raw))
+	dst = append(dst, FrameDelimiter)
+	dst = COBSEncode(dst, raw)
+	return append(dst, FrameDelimiter)
+}
+
+// ParseFrame decodes the COBS body found between two delimiters.
+func ParseFrame(body []byte) (Frame, error) {
+	raw, err := COBSDecode(nil, body)
+	if err != nil {
+		return Frame{}, err
+	}
+	if len(raw) < 6 {
+		return Frame{}, ErrFrameShort
+	}
+	n := len(raw) - 4
+	if crc32.ChecksumIEEE(raw[:n]) != binary.LittleEndian.Uint32(raw[n:]) {
+		return Frame{}, ErrFrameCRC
+	}
+	return Frame{Type: raw[0], Seq: raw[1], Payload: raw[2:n]}, nil
+}
+
+// MarshalBinary encodes the state as count-prefixed little endian fields:
+// axes as int16, triggers as uint8, buttons as a bitmask and hats as uint8.
+func (s *GamepadState) MarshalBinary() ([]byte, error) {
+	return s.AppendBinary(nil)
+}
+
+func (s *GamepadState) AppendBinary(b []byte) ([]byte, error) {
+	if len(s.Axes) > 0xff || len(s.Triggers) > 0xff || len(s.Buttons) > 0xff || len(s.Hats) > 0xff {
+		return b, errors.New("state: too many fields")
+	}
+	b = append(b, byte(len(s.Axes)))
+	for _, v := range s.Axes {
+		b = binary.LittleEndian.AppendUint16(b, uint16(int16(v)))
+	}
+	b = append(b, byte(len(s.Triggers)))
+	for _, v := range s.Triggers {
+		b = append(b, uint8(v))
+	}
+	b = append(b, byte(len(s.Buttons)))
+	var mask byte
+	for i, v := range s.Buttons {
+		if v {
+			mask |= 1 << (i % 8)
+		}
+		if i%8 == 7 || i == len(s.Buttons)-1 {
+			b = append(b, mask)
+			mask = 0
+		}
+	}
+	b = append(b, byte(len(s.Hats)))
+	for _, v := range s.Hats {
+		b = append(b, uint8(v))
+	}
+	return b, nil
+}
+
+func (s *GamepadState) UnmarshalBinary(b []byte) error {
+	next := func(n int) ([]byte, error) {
+		if len(b) < n {
+			return nil, ErrFrameShort
+		}
+		v := b[:n]
+		b = b[n:]
+		return v, nil
+	}
+	n, err := next(1)
+	if err != nil {
+		return err
+	}
+	axes, err := next(int(n[0]) * 2)
+	if err != nil {
+		return err
+	}
+	s.Axes = make([]int, n[0])
+	for i := range s.Axes {
+		s.Axes[i] = int(int16(binary.LittleEndian.Uint16(axes[i*2:])))
+	}
+	if n, err = next(1); err != nil {
+		return err
+	}
+	triggers, err := next(int(n[0]))
+	if err != nil {
+		return err
+	}
+	s.Triggers = make([]int, n[0])
+	for i, v := range triggers {
+		s.Triggers[i] = int(v)
+	}
+	if n, err = next(1); err != nil {
+		return err
+	}
+	mask, err := next((int(n[0]) + 7) / 8)
+	if err != nil {
+		return err
+	}
+	s.Buttons = make([]bool, n[0])
+	for i := range s.Buttons {
+		s.Buttons[i] = mask[i/8]&(1<<(i%8)) != 0
+	}
+	if n, err = next(1); err != nil {
+		return err
+	}
+	hats, err := next(int(n[0]))
+	if err != nil {
+		return err
+	}
+	s.Hats = make([]int, n[0])
+	for i, v := range hats {
+		s.Hats[i] = int(v)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package protocol
+
+//go:generate easyjson -all protocol.go
+
+// Version is the protocol version spoken by the firmware. Hosts refuse
+// firmware reporting a different version.
+const Version = 2
+
+// GamepadState is a full snapshot of the emulated gamepad.
+type GamepadState struct {
+	Axes     []int  `json:"axes"`
+	Triggers []int  `json:"triggers"`
+	Buttons  []bool `json:"buttons"`
+	Hats     []int  `json:"hats"`
+}
+
+const (
+	EncodingJSON   = "json"
+	EncodingBinary = "binary"
+)
+
+// FrameStats counts binary frames received by the firmware.
+type FrameStats struct {
+	Encoding string `json:"encoding"`
+	Frames   int    `json:"frames"`
+	Errors   int    `json:"errors"`
+	Lost     int    `json:"lost"`
+	Rejected int    `json:"rejected"`
+}
+
+// Layout describes the HID report of the emulated gamepad.
+type Layout struct {
+	Axes       int `json:"axes"`
+	AxisMin    int `json:"axisMin"`
+	AxisMax    int `json:"axisMax"`
+	Triggers   int `json:"triggers"`
+	TriggerMin int `json:"triggerMin"`
+	TriggerMax int `json:"triggerMax"`
+	Buttons    int `json:"buttons"`
+	Hats       int `json:"hats"`
+}
+
+// AxisValue converts a normalized -1..1 value to the raw axis range,
+// saturating values outside of it.
+func (l Layout) AxisValue(v float64) int {
+	v = min(max(v, -1), 1)
+	mid := float64(l.AxisMin+l.AxisMax) / 2
+	return int(mid + v*float64(l.AxisMax-l.AxisMin)/2)
+}
+
+// AxisFloat converts a raw axis value to -1..1.
+func (l Layout) AxisFloat(v int) float64 {
+	mid := float64(l.AxisMin+l.AxisMax) / 2
+	return (float64(v) - mid) * 2 / float64(l.AxisMax-l.AxisMin)
+}
+
+// TriggerValue converts a normalized 0..1 value to the raw trigger range,
+// saturating values outside of it.
+func (l Layout) TriggerValue(v float64) int {
+	v = min(max(v, 0), 1)
+	return l.TriggerMin + int(v*float64(l.TriggerMax-l.TriggerMin))
+}
+
+// Info is returned by the Hello and GetInfo methods.
+type Info struct {
+	Firmware     string   `json:"firmware"`
+	Protocol     int      `json:"protocol"`
+	VendorID     int      `json:"vendorId"`
+	ProductID    int      `json:"productId"`
+	Manufacturer string   `json:"manufacturer"`
+	Product      string   `json:"product"`
+	Serial       string   `json:"serial"`
+	Profile      string   `json:"profile"`
+	Pads         int      `json:"pads"`
+	Layout       Layout   `json:"layout"`
+	Methods      []string `json:"methods"`
+	Encodings    []string `json:"encodings"`
+}
+
+// WatchdogStatus reports the firmware watchdog. Durations are milliseconds;
+// a zero Timeout disables the silence check. The firmware starts with a
+// 1000 ms timeout, so hosts that do not send a message at least once a
+// second must call SetWatchdog first. A trip cancels running macros and
+// neutralizes every pad.
+type WatchdogStatus struct {
+	Timeout   int    `json:"timeout"`
+	Idle      int    `json:"idle"`
+	Tripped   bool   `json:"tripped"`
+	Trips     int    `json:"trips"`
+	Reason    string `json:"reason,omitempty"`
+	LineState bool   `json:"lineState"`
+}
+
+// SwitchStatus is the debounced state of an on-board switch and the
+// gamepad button it is merged into, -1 if none.
+type SwitchStatus struct {
+	Pressed bool `json:"pressed"`
+	Button  int  `json:"button"`
+}
+
+//easyjson:json
+type SwitchStatuses []SwitchStatus
+
+// LED modes reported by LEDs.
+const (
+	LEDDefault = "default" // driven by the firmware, see LEDStatus.Default
+	LEDOn      = "on"
+	LEDOff     = "off"
+	LEDPattern = "pattern" // blinking or a host supplied pattern
+)
+
+// Firmware functions an LED shows while in LEDDefault mode.
+const (
+	LEDHeartbeat = "heartbeat" // short flash every second
+	LEDActivity  = "activity"  // flashes on each message from the host
+	LEDWatchdog  = "watchdog"  // lit while the watchdog is tripped
+)
+
+type LEDStatus struct {
+	Mode    string `json:"mode"`
+	Default string `json:"default,omitempty"`
+}
+
+//easyjson:json
+type LEDStatuses []LEDStatus
+
+// Notifications sent by the firmware, interleaved with replies. They carry
+// no id and are never answered.
+const (
+	NotifySwitch          = "Switch"          // {index, pressed}
+	NotifyWatchdogTripped = "WatchdogTripped" // {reason}
+	NotifyUSBSuspend      = "USBSuspend"      // {}
+	NotifyUSBResume       = "USBResume"       // {}
+	NotifyOutputReport    = "OutputReport"    // {reportId, data (hex)}
+	NotifyMacroDone       = "MacroDone"       // {name, pad, cancelled}
+)
+
+// MacroStatus describes an uploaded macro. Step is the running step,
+// Repeat the runs left after the current one and Pad the pad it runs on.
+type MacroStatus struct {
+	Name     string `json:"name"`
+	Steps    int    `json:"steps"`
+	Duration int    `json:"duration"`
+	Running  bool   `json:"running"`
+	Step     int    `json:"step"`
+	Repeat   int    `json:"repeat"`
+	Pad      int    `json:"pad"`
+}
+
+//easyjson:json
+type MacroStatuses []MacroStatus
+
+// Button modes evaluated by the firmware.
+const (
+	ButtonNormal = "normal" // pressed while held
+	ButtonToggle = "toggle" // each press flips the button
+	ButtonTurbo  = "turbo"  // repeatedly pressed at Rate (0.1..50 Hz) while held
+)
+
+// ButtonMode is the mode of a button; Rate is in presses per second.
+type ButtonMode struct {
+	Mode string  `json:"mode"`
+	Rate float64 `json:"rate,omitempty"`
+}
+
+//easyjson:json
+type ButtonModes []ButtonMode
+
+// Axis interpolation modes.
+const (
+	InterpolateOff    = "off"
+	InterpolateLinear = "linear" // Rate is the axis speed in units per second
+	InterpolateDamped = "damped" // Rate is the natural frequency in rad/s
+)
+
+// Interpolation configures how the firmware moves axes toward the values
+// set by the host, reporting every Interval milliseconds while they move.
+type Interpolation struct {
+	Mode     string  `json:"mode"`
+	Rate     float64 `json:"rate,omitempty"`
+	Interval int     `json:"interval,omitempty"`
+}
+
+// Reporting configures when the firmware sends reports on its own. With
+// neither OnChange nor Interval set, reports are sent by SendState only.
+// Otherwise reports are sent at most every Poll milliseconds, merging all
+// changes and SendState calls in between.
+type Reporting struct {
+	OnChange bool `json:"onChange"`
+	Interval int  `json:"interval"`
+	Poll     int  `json:"poll"`
+}
+
+// ReportStats counts reports sent and updates merged into another report.
+type ReportStats struct {
+	Sent      int `json:"sent"`
+	Coalesced int `json:"coalesced"`
+}
+
+// Device profiles selectable with SetProfile.
+const (
+	ProfileGamepad     = "gamepad"     // 4 axes, 2 triggers, 10 buttons, 2 hats
+	ProfileFlightStick = "flightstick" // stick, rudder, throttle, 32 buttons
+	ProfileWheel       = "wheel"       // steering, 3 pedals, 14 buttons
+)
+
+// ProfileStatus describes a profile. Next marks the profile the device
+// enumerates as after a reset.
+type ProfileStatus struct {
+	Name   string `json:"name"`
+	Layout Layout `json:"layout"`
+	Active bool   `json:"active"`
+	Next   bool   `json:"next"`
+}
+
+//easyjson:json
+type ProfileStatuses []ProfileStatus
+
+// MacroStep is one step of a stored macro; maps are keyed by input index.
+type MacroStep struct {
+	Axes     map[string]int  `json:"axes,omitempty"`
+	Triggers map[string]int  `json:"triggers,omitempty"`
+	Buttons  map[string]bool `json:"buttons,omitempty"`
+	Hats     map[string]int  `json:"hats,omitempty"`
+	Ms       int             `json:"ms"`
+}
+
+type MacroDef struct {
+	Name  string      `json:"name"`
+	Steps []MacroStep `json:"steps"`
+}
+
+// Settings are the device settings kept in flash by SaveSettings.
+// Watchdog is in milliseconds. Profile, Pads, Product and Serial are used on
+// the next enumeration; empty Product and Serial select the firmware
+// defaults. ButtonModes apply to every pad.
+type Settings struct {
+	Watchdog    int          `json:"watchdog"`
+	Profile     string       `json:"profile"`
+	Pads        int          `json:"pads,omitempty"`
+	Product     string       `json:"product"`
+	Serial      string       `json:"serial"`
+	ButtonModes []ButtonMode `json:"buttonModes"`
+	Macros      []MacroDef   `json:"macros"`
+}
@@ -0,0 +1,2484 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package protocol
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol(in *jlexer.Lexer, out *WatchdogStatus) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "timeout":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Timeout = int(in.Int())
+			}
+		case "idle":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Idle = int(in.Int())
+			}
+		case "tripped":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Tripped = bool(in.Bool())
+			}
+		case "trips":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Trips = int(in.Int())
+			}
+		case "reason":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Reason = string(in.String())
+			}
+		case "lineState":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.LineState = bool(in.Bool())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol(out *jwriter.Writer, in WatchdogStatus) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"timeout\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Timeout))
+	}
+	{
+		const prefix string = ",\"idle\":"
+		out.RawString(prefix)
+		out.Int(int(in.Idle))
+	}
+	{
+		const prefix string = ",\"tripped\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Tripped))
+	}
+	{
+		const prefix string = ",\"trips\":"
+		out.RawString(prefix)
+		out.Int(int(in.Trips))
+	}
+	if in.Reason != "" {
+		const prefix string = ",\"reason\":"
+		out.RawString(prefix)
+		out.String(string(in.Reason))
+	}
+	{
+		const prefix string = ",\"lineState\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.LineState))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v WatchdogStatus) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v WatchdogStatus) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *WatchdogStatus) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *WatchdogStatus) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol1(in *jlexer.Lexer, out *SwitchStatuses) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(SwitchStatuses, 0, 4)
+			} else {
+				*out = SwitchStatuses{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 SwitchStatus
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				(v1).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol1(out *jwriter.Writer, in SwitchStatuses) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			(v3).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v SwitchStatuses) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v SwitchStatuses) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *SwitchStatuses) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *SwitchStatuses) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol1(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol2(in *jlexer.Lexer, out *SwitchStatus) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "pressed":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Pressed = bool(in.Bool())
+			}
+		case "button":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Button = int(in.Int())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol2(out *jwriter.Writer, in SwitchStatus) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"pressed\":"
+		out.RawString(prefix[1:])
+		out.Bool(bool(in.Pressed))
+	}
+	{
+		const prefix string = ",\"button\":"
+		out.RawString(prefix)
+		out.Int(int(in.Button))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v SwitchStatus) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v SwitchStatus) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *SwitchStatus) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *SwitchStatus) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol2(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol3(in *jlexer.Lexer, out *Settings) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "watchdog":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Watchdog = int(in.Int())
+			}
+		case "profile":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Profile = string(in.String())
+			}
+		case "pads":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Pads = int(in.Int())
+			}
+		case "product":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Product = string(in.String())
+			}
+		case "serial":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Serial = string(in.String())
+			}
+		case "buttonModes":
+			if in.IsNull() {
+				in.Skip()
+				out.ButtonModes = nil
+			} else {
+				in.Delim('[')
+				if out.ButtonModes == nil {
+					if !in.IsDelim(']') {
+						out.ButtonModes = make([]ButtonMode, 0, 2)
+					} else {
+						out.ButtonModes = []ButtonMode{}
+					}
+				} else {
+					out.ButtonModes = (out.ButtonModes)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 ButtonMode
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						(v4).UnmarshalEasyJSON(in)
+					}
+					out.ButtonModes = append(out.ButtonModes, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "macros":
+			if in.IsNull() {
+				in.Skip()
+				out.Macros = nil
+			} else {
+				in.Delim('[')
+				if out.Macros == nil {
+					if !in.IsDelim(']') {
+						out.Macros = make([]MacroDef, 0, 1)
+					} else {
+						out.Macros = []MacroDef{}
+					}
+				} else {
+					out.Macros = (out.Macros)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v5 MacroDef
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						(v5).UnmarshalEasyJSON(in)
+					}
+					out.Macros = append(out.Macros, v5)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol3(out *jwriter.Writer, in Settings) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"watchdog\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Watchdog))
+	}
+	{
+		const prefix string = ",\"profile\":"
+		out.RawString(prefix)
+		out.String(string(in.Profile))
+	}
+	if in.Pads != 0 {
+		const prefix string = ",\"pads\":"
+		out.RawString(prefix)
+		out.Int(int(in.Pads))
+	}
+	{
+		const prefix string = ",\"product\":"
+		out.RawString(prefix)
+		out.String(string(in.Product))
+	}
+	{
+		const prefix string = ",\"serial\":"
+		out.RawString(prefix)
+		out.String(string(in.Serial))
+	}
+	{
+		const prefix string = ",\"buttonModes\":"
+		out.RawString(prefix)
+		if in.ButtonModes == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v6, v7 := range in.ButtonModes {
+				if v6 > 0 {
+					out.RawByte(',')
+				}
+				(v7).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"macros\":"
+		out.RawString(prefix)
+		if in.Macros == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v8, v9 := range in.Macros {
+				if v8 > 0 {
+					out.RawByte(',')
+				}
+				(v9).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Settings) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Settings) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Settings) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Settings) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol3(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol4(in *jlexer.Lexer, out *Reporting) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "onChange":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.OnChange = bool(in.Bool())
+			}
+		case "interval":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Interval = int(in.Int())
+			}
+		case "poll":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Poll = int(in.Int())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol4(out *jwriter.Writer, in Reporting) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"onChange\":"
+		out.RawString(prefix[1:])
+		out.Bool(bool(in.OnChange))
+	}
+	{
+		const prefix string = ",\"interval\":"
+		out.RawString(prefix)
+		out.Int(int(in.Interval))
+	}
+	{
+		const prefix string = ",\"poll\":"
+		out.RawString(prefix)
+		out.Int(int(in.Poll))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Reporting) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Reporting) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Reporting) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Reporting) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol4(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol5(in *jlexer.Lexer, out *ReportStats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "sent":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Sent = int(in.Int())
+			}
+		case "coalesced":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Coalesced = int(in.Int())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol5(out *jwriter.Writer, in ReportStats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"sent\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Sent))
+	}
+	{
+		const prefix string = ",\"coalesced\":"
+		out.RawString(prefix)
+		out.Int(int(in.Coalesced))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ReportStats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol5(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ReportStats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol5(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ReportStats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol5(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ReportStats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol5(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol6(in *jlexer.Lexer, out *ProfileStatuses) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(ProfileStatuses, 0, 0)
+			} else {
+				*out = ProfileStatuses{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v10 ProfileStatus
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				(v10).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v10)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol6(out *jwriter.Writer, in ProfileStatuses) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v11, v12 := range in {
+			if v11 > 0 {
+				out.RawByte(',')
+			}
+			(v12).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ProfileStatuses) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol6(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ProfileStatuses) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol6(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ProfileStatuses) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol6(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ProfileStatuses) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol6(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol7(in *jlexer.Lexer, out *ProfileStatus) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "name":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Name = string(in.String())
+			}
+		case "layout":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				(out.Layout).UnmarshalEasyJSON(in)
+			}
+		case "active":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Active = bool(in.Bool())
+			}
+		case "next":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Next = bool(in.Bool())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol7(out *jwriter.Writer, in ProfileStatus) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"layout\":"
+		out.RawString(prefix)
+		(in.Layout).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"active\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Active))
+	}
+	{
+		const prefix string = ",\"next\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Next))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ProfileStatus) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol7(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ProfileStatus) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol7(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ProfileStatus) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol7(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ProfileStatus) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol7(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol8(in *jlexer.Lexer, out *MacroStep) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "axes":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Axes = make(map[string]int)
+				} else {
+					out.Axes = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v13 int
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v13 = int(in.Int())
+					}
+					(out.Axes)[key] = v13
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "triggers":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Triggers = make(map[string]int)
+				} else {
+					out.Triggers = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v14 int
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v14 = int(in.Int())
+					}
+					(out.Triggers)[key] = v14
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "buttons":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Buttons = make(map[string]bool)
+				} else {
+					out.Buttons = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v15 bool
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v15 = bool(in.Bool())
+					}
+					(out.Buttons)[key] = v15
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "hats":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Hats = make(map[string]int)
+				} else {
+					out.Hats = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v16 int
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v16 = int(in.Int())
+					}
+					(out.Hats)[key] = v16
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "ms":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Ms = int(in.Int())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol8(out *jwriter.Writer, in MacroStep) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if len(in.Axes) != 0 {
+		const prefix string = ",\"axes\":"
+		first = false
+		out.RawString(prefix[1:])
+		{
+			out.RawByte('{')
+			v17First := true
+			for v17Name, v17Value := range in.Axes {
+				if v17First {
+					v17First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v17Name))
+				out.RawByte(':')
+				out.Int(int(v17Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.Triggers) != 0 {
+		const prefix string = ",\"triggers\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('{')
+			v18First := true
+			for v18Name, v18Value := range in.Triggers {
+				if v18First {
+					v18First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v18Name))
+				out.RawByte(':')
+				out.Int(int(v18Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.Buttons) != 0 {
+		const prefix string = ",\"buttons\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('{')
+			v19First := true
+			for v19Name, v19Value := range in.Buttons {
+				if v19First {
+					v19First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v19Name))
+				out.RawByte(':')
+				out.Bool(bool(v19Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.Hats) != 0 {
+		const prefix string = ",\"hats\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('{')
+			v20First := true
+			for v20Name, v20Value := range in.Hats {
+				if v20First {
+					v20First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v20Name))
+				out.RawByte(':')
+				out.Int(int(v20Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"ms\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Ms))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v MacroStep) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol8(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v MacroStep) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol8(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *MacroStep) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol8(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *MacroStep) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol8(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol9(in *jlexer.Lexer, out *MacroStatuses) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(MacroStatuses, 0, 1)
+			} else {
+				*out = MacroStatuses{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v21 MacroStatus
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				(v21).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v21)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol9(out *jwriter.Writer, in MacroStatuses) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v22, v23 := range in {
+			if v22 > 0 {
+				out.RawByte(',')
+			}
+			(v23).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v MacroStatuses) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol9(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v MacroStatuses) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol9(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *MacroStatuses) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol9(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *MacroStatuses) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol9(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol10(in *jlexer.Lexer, out *MacroStatus) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "name":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Name = string(in.String())
+			}
+		case "steps":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Steps = int(in.Int())
+			}
+		case "duration":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Duration = int(in.Int())
+			}
+		case "running":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Running = bool(in.Bool())
+			}
+		case "step":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Step = int(in.Int())
+			}
+		case "repeat":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Repeat = int(in.Int())
+			}
+		case "pad":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Pad = int(in.Int())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol10(out *jwriter.Writer, in MacroStatus) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"steps\":"
+		out.RawString(prefix)
+		out.Int(int(in.Steps))
+	}
+	{
+		const prefix string = ",\"duration\":"
+		out.RawString(prefix)
+		out.Int(int(in.Duration))
+	}
+	{
+		const prefix string = ",\"running\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Running))
+	}
+	{
+		const prefix string = ",\"step\":"
+		out.RawString(prefix)
+		out.Int(int(in.Step))
+	}
+	{
+		const prefix string = ",\"repeat\":"
+		out.RawString(prefix)
+		out.Int(int(in.Repeat))
+	}
+	{
+		const prefix string = ",\"pad\":"
+		out.RawString(prefix)
+		out.Int(int(in.Pad))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v MacroStatus) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol10(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v MacroStatus) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol10(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *MacroStatus) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol10(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *MacroStatus) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol10(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol11(in *jlexer.Lexer, out *MacroDef) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "name":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Name = string(in.String())
+			}
+		case "steps":
+			if in.IsNull() {
+				in.Skip()
+				out.Steps = nil
+			} else {
+				in.Delim('[')
+				if out.Steps == nil {
+					if !in.IsDelim(']') {
+						out.Steps = make([]MacroStep, 0, 1)
+					} else {
+						out.Steps = []MacroStep{}
+					}
+				} else {
+					out.Steps = (out.Steps)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v24 MacroStep
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						(v24).UnmarshalEasyJSON(in)
+					}
+					out.Steps = append(out.Steps, v24)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol11(out *jwriter.Writer, in MacroDef) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"steps\":"
+		out.RawString(prefix)
+		if in.Steps == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v25, v26 := range in.Steps {
+				if v25 > 0 {
+					out.RawByte(',')
+				}
+				(v26).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v MacroDef) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol11(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v MacroDef) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol11(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *MacroDef) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol11(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *MacroDef) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol11(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol12(in *jlexer.Lexer, out *Layout) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "axes":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Axes = int(in.Int())
+			}
+		case "axisMin":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.AxisMin = int(in.Int())
+			}
+		case "axisMax":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.AxisMax = int(in.Int())
+			}
+		case "triggers":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Triggers = int(in.Int())
+			}
+		case "triggerMin":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.TriggerMin = int(in.Int())
+			}
+		case "triggerMax":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.TriggerMax = int(in.Int())
+			}
+		case "buttons":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Buttons = int(in.Int())
+			}
+		case "hats":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Hats = int(in.Int())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol12(out *jwriter.Writer, in Layout) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"axes\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Axes))
+	}
+	{
+		const prefix string = ",\"axisMin\":"
+		out.RawString(prefix)
+		out.Int(int(in.AxisMin))
+	}
+	{
+		const prefix string = ",\"axisMax\":"
+		out.RawString(prefix)
+		out.Int(int(in.AxisMax))
+	}
+	{
+		const prefix string = ",\"triggers\":"
+		out.RawString(prefix)
+		out.Int(int(in.Triggers))
+	}
+	{
+		const prefix string = ",\"triggerMin\":"
+		out.RawString(prefix)
+		out.Int(int(in.TriggerMin))
+	}
+	{
+		const prefix string = ",\"triggerMax\":"
+		out.RawString(prefix)
+		out.Int(int(in.TriggerMax))
+	}
+	{
+		const prefix string = ",\"buttons\":"
+		out.RawString(prefix)
+		out.Int(int(in.Buttons))
+	}
+	{
+		const prefix string = ",\"hats\":"
+		out.RawString(prefix)
+		out.Int(int(in.Hats))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Layout) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol12(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Layout) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol12(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Layout) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol12(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Layout) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol12(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol13(in *jlexer.Lexer, out *LEDStatuses) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(LEDStatuses, 0, 2)
+			} else {
+				*out = LEDStatuses{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v27 LEDStatus
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				(v27).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v27)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol13(out *jwriter.Writer, in LEDStatuses) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v28, v29 := range in {
+			if v28 > 0 {
+				out.RawByte(',')
+			}
+			(v29).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LEDStatuses) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol13(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LEDStatuses) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol13(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LEDStatuses) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol13(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LEDStatuses) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol13(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol14(in *jlexer.Lexer, out *LEDStatus) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "mode":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Mode = string(in.String())
+			}
+		case "default":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Default = string(in.String())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol14(out *jwriter.Writer, in LEDStatus) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"mode\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Mode))
+	}
+	if in.Default != "" {
+		const prefix string = ",\"default\":"
+		out.RawString(prefix)
+		out.String(string(in.Default))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LEDStatus) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol14(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LEDStatus) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol14(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LEDStatus) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol14(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LEDStatus) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol14(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol15(in *jlexer.Lexer, out *Interpolation) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "mode":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Mode = string(in.String())
+			}
+		case "rate":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Rate = float64(in.Float64())
+			}
+		case "interval":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Interval = int(in.Int())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol15(out *jwriter.Writer, in Interpolation) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"mode\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Mode))
+	}
+	if in.Rate != 0 {
+		const prefix string = ",\"rate\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Rate))
+	}
+	if in.Interval != 0 {
+		const prefix string = ",\"interval\":"
+		out.RawString(prefix)
+		out.Int(int(in.Interval))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Interpolation) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol15(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Interpolation) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol15(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Interpolation) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol15(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Interpolation) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol15(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol16(in *jlexer.Lexer, out *Info) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "firmware":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Firmware = string(in.String())
+			}
+		case "protocol":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Protocol = int(in.Int())
+			}
+		case "vendorId":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.VendorID = int(in.Int())
+			}
+		case "productId":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.ProductID = int(in.Int())
+			}
+		case "manufacturer":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Manufacturer = string(in.String())
+			}
+		case "product":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Product = string(in.String())
+			}
+		case "serial":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Serial = string(in.String())
+			}
+		case "profile":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Profile = string(in.String())
+			}
+		case "pads":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Pads = int(in.Int())
+			}
+		case "layout":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				(out.Layout).UnmarshalEasyJSON(in)
+			}
+		case "methods":
+			if in.IsNull() {
+				in.Skip()
+				out.Methods = nil
+			} else {
+				in.Delim('[')
+				if out.Methods == nil {
+					if !in.IsDelim(']') {
+						out.Methods = make([]string, 0, 4)
+					} else {
+						out.Methods = []string{}
+					}
+				} else {
+					out.Methods = (out.Methods)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v30 string
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v30 = string(in.String())
+					}
+					out.Methods = append(out.Methods, v30)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "encodings":
+			if in.IsNull() {
+				in.Skip()
+				out.Encodings = nil
+			} else {
+				in.Delim('[')
+				if out.Encodings == nil {
+					if !in.IsDelim(']') {
+						out.Encodings = make([]string, 0, 4)
+					} else {
+						out.Encodings = []string{}
+					}
+				} else {
+					out.Encodings = (out.Encodings)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v31 string
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v31 = string(in.String())
+					}
+					out.Encodings = append(out.Encodings, v31)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol16(out *jwriter.Writer, in Info) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"firmware\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Firmware))
+	}
+	{
+		const prefix string = ",\"protocol\":"
+		out.RawString(prefix)
+		out.Int(int(in.Protocol))
+	}
+	{
+		const prefix string = ",\"vendorId\":"
+		out.RawString(prefix)
+		out.Int(int(in.VendorID))
+	}
+	{
+		const prefix string = ",\"productId\":"
+		out.RawString(prefix)
+		out.Int(int(in.ProductID))
+	}
+	{
+		const prefix string = ",\"manufacturer\":"
+		out.RawString(prefix)
+		out.String(string(in.Manufacturer))
+	}
+	{
+		const prefix string = ",\"product\":"
+		out.RawString(prefix)
+		out.String(string(in.Product))
+	}
+	{
+		const prefix string = ",\"serial\":"
+		out.RawString(prefix)
+		out.String(string(in.Serial))
+	}
+	{
+		const prefix string = ",\"profile\":"
+		out.RawString(prefix)
+		out.String(string(in.Profile))
+	}
+	{
+		const prefix string = ",\"pads\":"
+		out.RawString(prefix)
+		out.Int(int(in.Pads))
+	}
+	{
+		const prefix string = ",\"layout\":"
+		out.RawString(prefix)
+		(in.Layout).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"methods\":"
+		out.RawString(prefix)
+		if in.Methods == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v32, v33 := range in.Methods {
+				if v32 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v33))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"encodings\":"
+		out.RawString(prefix)
+		if in.Encodings == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v34, v35 := range in.Encodings {
+				if v34 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v35))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Info) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol16(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Info) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol16(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Info) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol16(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Info) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol16(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol17(in *jlexer.Lexer, out *GamepadState) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "axes":
+			if in.IsNull() {
+				in.Skip()
+				out.Axes = nil
+			} else {
+				in.Delim('[')
+				if out.Axes == nil {
+					if !in.IsDelim(']') {
+						out.Axes = make([]int, 0, 8)
+					} else {
+						out.Axes = []int{}
+					}
+				} else {
+					out.Axes = (out.Axes)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v36 int
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v36 = int(in.Int())
+					}
+					out.Axes = append(out.Axes, v36)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "triggers":
+			if in.IsNull() {
+				in.Skip()
+				out.Triggers = nil
+			} else {
+				in.Delim('[')
+				if out.Triggers == nil {
+					if !in.IsDelim(']') {
+						out.Triggers = make([]int, 0, 8)
+					} else {
+						out.Triggers = []int{}
+					}
+				} else {
+					out.Triggers = (out.Triggers)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v37 int
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v37 = int(in.Int())
+					}
+					out.Triggers = append(out.Triggers, v37)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "buttons":
+			if in.IsNull() {
+				in.Skip()
+				out.Buttons = nil
+			} else {
+				in.Delim('[')
+				if out.Buttons == nil {
+					if !in.IsDelim(']') {
+						out.Buttons = make([]bool, 0, 64)
+					} else {
+						out.Buttons = []bool{}
+					}
+				} else {
+					out.Buttons = (out.Buttons)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v38 bool
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v38 = bool(in.Bool())
+					}
+					out.Buttons = append(out.Buttons, v38)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "hats":
+			if in.IsNull() {
+				in.Skip()
+				out.Hats = nil
+			} else {
+				in.Delim('[')
+				if out.Hats == nil {
+					if !in.IsDelim(']') {
+						out.Hats = make([]int, 0, 8)
+					} else {
+						out.Hats = []int{}
+					}
+				} else {
+					out.Hats = (out.Hats)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v39 int
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v39 = int(in.Int())
+					}
+					out.Hats = append(out.Hats, v39)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol17(out *jwriter.Writer, in GamepadState) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"axes\":"
+		out.RawString(prefix[1:])
+		if in.Axes == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v40, v41 := range in.Axes {
+				if v40 > 0 {
+					out.RawByte(',')
+				}
+				out.Int(int(v41))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"triggers\":"
+		out.RawString(prefix)
+		if in.Triggers == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v42, v43 := range in.Triggers {
+				if v42 > 0 {
+					out.RawByte(',')
+				}
+				out.Int(int(v43))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"buttons\":"
+		out.RawString(prefix)
+		if in.Buttons == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v44, v45 := range in.Buttons {
+				if v44 > 0 {
+					out.RawByte(',')
+				}
+				out.Bool(bool(v45))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"hats\":"
+		out.RawString(prefix)
+		if in.Hats == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v46, v47 := range in.Hats {
+				if v46 > 0 {
+					out.RawByte(',')
+				}
+				out.Int(int(v47))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v GamepadState) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol17(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v GamepadState) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol17(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *GamepadState) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol17(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *GamepadState) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol17(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol18(in *jlexer.Lexer, out *FrameStats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "encoding":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Encoding = string(in.String())
+			}
+		case "frames":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Frames = int(in.Int())
+			}
+		case "errors":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Errors = int(in.Int())
+			}
+		case "lost":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Lost = int(in.Int())
+			}
+		case "rejected":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Rejected = int(in.Int())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol18(out *jwriter.Writer, in FrameStats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"encoding\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Encoding))
+	}
+	{
+		const prefix string = ",\"frames\":"
+		out.RawString(prefix)
+		out.Int(int(in.Frames))
+	}
+	{
+		const prefix string = ",\"errors\":"
+		out.RawString(prefix)
+		out.Int(int(in.Errors))
+	}
+	{
+		const prefix string = ",\"lost\":"
+		out.RawString(prefix)
+		out.Int(int(in.Lost))
+	}
+	{
+		const prefix string = ",\"rejected\":"
+		out.RawString(prefix)
+		out.Int(int(in.Rejected))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v FrameStats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol18(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v FrameStats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol18(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *FrameStats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol18(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *FrameStats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol18(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol19(in *jlexer.Lexer, out *ButtonModes) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(ButtonModes, 0, 2)
+			} else {
+				*out = ButtonModes{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v48 ButtonMode
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				(v48).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v48)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol19(out *jwriter.Writer, in ButtonModes) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v49, v50 := range in {
+			if v49 > 0 {
+				out.RawByte(',')
+			}
+			(v50).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ButtonModes) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol19(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ButtonModes) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol19(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ButtonModes) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol19(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ButtonModes) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol19(l, v)
+}
+func easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol20(in *jlexer.Lexer, out *ButtonMode) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "mode":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Mode = string(in.String())
+			}
+		case "rate":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Rate = float64(in.Float64())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol20(out *jwriter.Writer, in ButtonMode) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"mode\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Mode))
+	}
+	if in.Rate != 0 {
+		const prefix string = ",\"rate\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Rate))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ButtonMode) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol20(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ButtonMode) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE4425964EncodeGithubComNobonoboGamepadEmulatorProtocol20(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ButtonMode) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol20(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ButtonMode) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE4425964DecodeGithubComNobonoboGamepadEmulatorProtocol20(l, v)
+}
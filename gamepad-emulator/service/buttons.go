@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+// Turbo rates in presses per second. Rates near zero overflow the half
+// period; faster ones exceed what the USB poll interval can show.
+const (
+	defaultTurboRate = 10.0
+	minTurboRate     = 0.1
+	maxTurboRate     = 50.0
+)
+
+var ErrButtonMode = errors.New("invalid button mode")
+
+// buttonMode holds the mode of a button and the input level it was last
+// evaluated with. The level is the host's button or a PressFor pulse.
+type buttonMode struct {
+	mode    string
+	rate    float64
+	level   bool
+	since   time.Time // when level last changed
+	until   time.Time // end of a PressFor pulse
+	latched bool      // toggle state
+	out     bool      // last reported value
+}
+
+func (j *JS) ButtonMode(index int) (protocol.ButtonMode, error) {
+	if err := checkRange("button index", index, 0, len(j.buttons)-1); err != nil {
+		return protocol.ButtonMode{}, err
+	}
+	m := &j.modes[index]
+	return protocol.ButtonMode{Mode: m.mode, Rate: m.rate}, nil
+}
+
+// SetButtonMode changes how the firmware turns presses of a button into
+// reports. Changing the mode releases a latched toggle.
+func (j *JS) SetButtonMode(index int, mode protocol.ButtonMode) error {
+	if err := checkRange("button index", index, 0, len(j.buttons)-1); err != nil {
+		return err
+	}
+	mode, err := checkButtonMode(mode)
+	if err != nil {
+		return err
+	}
+	m := &j.modes[index]
+	m.mode = mode.Mode
+	m.rate = mode.Rate
+	m.latched = false
+	return nil
+}
+
+// checkButtonMode validates mode and fills in the default turbo rate.
+func checkButtonMode(mode protocol.ButtonMode) (protocol.ButtonMode, error) {
+	switch mode.Mode {
+	case protocol.ButtonNormal, protocol.ButtonToggle:
+		mode.Rate = 0
+	case protocol.ButtonTurbo:
+		if mode.Rate == 0 {
+			mode.Rate = defaultTurboRate
+		}
+		if !(mode.Rate >= minTurboRate && mode.Rate <= maxTurboRate) {
+			return mode, fmt.Errorf("%w: turbo rate out of range: %g (want %g..%g)", ErrButtonMode, mode.Rate, minTurboRate, maxTurboRate)
+		}
+	default:
+		return mode, fmt.Errorf("%w: %q", ErrButtonMode, mode.Mode)
+	}
+	return mode, nil
+}
+
+// PressFor holds a button for d as if the host pressed it, then releases
+// it. The button's mode applies, so on a toggle button it flips the state.
+func (j *JS) PressFor(index int, d time.Duration) error {
+	if err := checkRange("button index", index, 0, len(j.buttons)-1); err != nil {
+		return err
+	}
+	now := time.Now()
+	j.modes[index].until = now.Add(d)
+	j.evalButton(index, now)
+	return nil
+}
+
+// evalButton feeds the current input level of a button to its mode.
+func (j *JS) evalButton(index int, now time.Time) {
+	m := &j.modes[index]
+	level := j.buttons[index] || now.Before(m.until)
+	if level == m.level {
+		return
+	}
+	m.level = level
+	m.since = now
+	if level && m.mode == protocol.ButtonToggle {
+		m.latched = !m.latched
+	}
+}
+
+// pressed returns the reported value of a button at now.
+func (j *JS) pressed(index int, now time.Time) bool {
+	m := &j.modes[index]
+	switch m.mode {
+	case protocol.ButtonToggle:
+		return m.latched
+	case protocol.ButtonTurbo:
+		if !m.level {
+			return false
+		}
+		half := time.Duration(float64(time.Second) / m.rate / 2)
+		return now.Sub(m.since)/half%2 == 0
+	}
+	return m.level
+}
+
+// Tick ends PressFor pulses and advances turbo buttons and interpolated
+// axes, sending a report when that changes what the PC sees, and sends
+// reports due in the automatic reporting mode and queued typing.
+func (j *JS) Tick(now time.Time) {
+	changed := j.stepAxes(now)
+	for i := range j.modes {
+		m := &j.modes[i]
+		if !m.until.IsZero() && !now.Before(m.until) {
+			m.until = time.Time{}
+			j.evalButton(i, now)
+		}
+		if j.pressed(i, now) != m.out {
+			changed = true
+		}
+	}
+	if changed {
+		j.SendState()
+	}
+	j.flush(now)
+	j.typeNext(now)
+}
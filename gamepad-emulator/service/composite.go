@@ -0,0 +1,311 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/nobonobo/gamepad-emulator/hid"
+)
+
+// Report ids of the interfaces that share the gamepad's HID descriptor.
+const (
+	reportMouse    = 2
+	reportPointer  = 3 // absolute pointer
+	reportKeyboard = 4
+)
+
+const (
+	mouseButtons = 5
+	pointerMax   = 32767
+	// maxMouseMove bounds the deltas of one MouseMove, which are sent
+	// synchronously, 127 units per report.
+	maxMouseMove = 32767
+	maxTyping    = 256
+	// typeInterval spaces typed key reports so that hosts polling slower
+	// than the endpoint interval see every press and release.
+	typeInterval = 8 * time.Millisecond
+)
+
+var (
+	ErrKeyboard = errors.New("invalid key")
+	ErrPointer  = errors.New("invalid pointer position")
+)
+
+type keyEvent struct {
+	code int
+	mods uint8
+	down bool
+}
+
+type composite struct {
+	buttons uint8
+	mods    uint8
+	keys    [6]uint8
+	typing  []keyEvent
+	typed   time.Time
+	buf     [8]byte
+}
+
+func (j *JS) sendMouse(dx, dy, wheel int) {
+	c := &j.comp
+	c.buf[0] = c.buttons
+	c.buf[1] = uint8(int8(dx))
+	c.buf[2] = uint8(int8(dy))
+	c.buf[3] = uint8(int8(wheel))
+	j.js.SendReport(reportMouse, c.buf[:4])
+}
+
+// MouseMove moves the mouse relatively, split into as many reports as the
+// 8 bit deltas need.
+func (j *JS) MouseMove(dx, dy, wheel int) error {
+	if err := checkRange("dx", dx, -maxMouseMove, maxMouseMove); err != nil {
+		return err
+	}
+	if err := checkRange("dy", dy, -maxMouseMove, maxMouseMove); err != nil {
+		return err
+	}
+	if err := checkRange("wheel", wheel, -maxMouseMove, maxMouseMove); err != nil {
+		return err
+	}
+	for first := true; first || dx != 0 || dy != 0 || wheel != 0; first = false {
+		x := min(max(dx, -127), 127)
+		y := min(max(dy, -127), 127)
+		w := min(max(wheel, -127), 127)
+		j.sendMouse(x, y, w)
+		dx, dy, wheel = dx-x, dy-y, wheel-w
+	}
+	return nil
+}
+
+func (j *JS) MouseButton(index int, push bool) error {
+	if err := checkRange("mouse button", index, 0, mouseButtons-1); err != nil {
+		return err
+	}
+	if push {
+		j.comp.buttons |= 1 << index
+	} else {
+		j.comp.buttons &^= 1 << index
+	}
+	j.sendMouse(0, 0, 0)
+	return nil
+}
+
+// MouseAbs moves the pointer to x, y in 0..1 of the screen.
+func (j *JS) MouseAbs(x, y float64) error {
+	if math.IsNaN(x) || math.IsNaN(y) || x < 0 || x > 1 || y < 0 || y > 1 {
+		return fmt.Errorf("%w: %g,%g out of 0..1", ErrPointer, x, y)
+	}
+	c := &j.comp
+	c.buf[0] = c.buttons
+	binary16(c.buf[1:], int(math.Round(x*pointerMax)))
+	binary16(c.buf[3:], int(math.Round(y*pointerMax)))
+	j.js.SendReport(reportPointer, c.buf[:5])
+	return nil
+}
+
+func binary16(b []byte, v int) {
+	b[0] = byte(v)
+	b[1] = byte(v >> 8)
+}
+
+func (j *JS) sendKeyboard(mods uint8, keys []uint8) {
+	c := &j.comp
+	c.buf = [8]byte{mods}
+	copy(c.buf[2:], keys)
+	j.js.SendReport(reportKeyboard, c.buf[:])
+}
+
+func (j *JS) sendKeys() {
+	j.sendKeyboard(j.comp.mods, j.comp.keys[:])
+}
+
+// KeyDown presses a key by usage; modifiers set their bit.
+func (j *JS) KeyDown(code int) error {
+	if err := checkRange("key code", code, 1, hid.KeyRightGUI); err != nil {
+		return err
+	}
+	c := &j.comp
+	if code >= hid.KeyLeftCtrl {
+		c.mods |= 1 << (code - hid.KeyLeftCtrl)
+		j.sendKeys()
+		return nil
+	}
+	free := -1
+	for i, k := range c.keys {
+		if int(k) == code {
+			return nil
+		}
+		if k == 0 && free < 0 {
+			free = i
+		}
+	}
+	if free < 0 {
+		return fmt.Errorf("%w: more than %d keys held", ErrKeyboard, len(c.keys))
+	}
+	c.keys[free] = uint8(code)
+	j.sendKeys()
+	return nil
+}
+
+func (j *JS) KeyUp(code int) error {
+	if err := checkRange("key code", code, 1, hid.KeyRightGUI); err != nil {
+		return err
+	}
+	c := &j.comp
+	if code >= hid.KeyLeftCtrl {
+		c.mods &^= 1 << (code - hid.KeyLeftCtrl)
+	}
+	for i, k := range c.keys {
+		if int(k) == code {
+			c.keys[i] = 0
+		}
+	}
+	j.sendKeys()
+	return nil
+}
+
+// TypeString queues text to be typed on a US layout. Keys are pressed and
+// released one report at a time from Tick.
+func (j *JS) TypeString(text string) error {
+	c := &j.comp
+	events := make([]keyEvent, 0, 2*len(text))
+	for _, r := range text {
+		code, mods, ok := hid.KeyCode(r)
+		if !ok {
+			return fmt.Errorf("%w: cannot type %q", ErrKeyboard, r)
+		}
+		events = append(events,
+			keyEvent{code: code, mods: uint8(mods), down: true},
+			keyEvent{code: code})
+	}
+	if len(c.typing)+len(events) > 2*maxTyping {
+		return fmt.Errorf("%w: more than %d characters queued", ErrKeyboard, maxTyping)
+	}
+	c.typing = append(c.typing, events...)
+	return nil
+}
+
+// typeNext sends the next queued key report when it is due.
+func (j *JS) typeNext(now time.Time) {
+	c := &j.comp
+	if len(c.typing) == 0 || now.Sub(c.typed) < typeInterval {
+		return
+	}
+	e := c.typing[0]
+	c.typing = c.typing[1:]
+	c.typed = now
+	if e.down {
+		keys := [7]uint8{uint8(e.code)}
+		copy(keys[1:], c.keys[:])
+		j.sendKeyboard(c.mods|e.mods, keys[:6])
+	} else {
+		j.sendKeys()
+	}
+}
+
+// releaseComposite releases held mouse buttons and keys and drops queued
+// typing.
+func (j *JS) releaseComposite() {
+	c := &j.comp
+	c.typing = nil
+	if c.buttons != 0 {
+		c.buttons = 0
+		j.sendMouse(0, 0, 0)
+	}
+	if c.mods != 0 || c.keys != [len(c.keys)]uint8{} {
+		c.mods = 0
+		c.keys = [len(c.keys)]uint8{}
+		j.sendKeys()
+	}
+}
+
+var compositeDesc = hid.Append(
+	hid.UsagePage(hid.PageGenericDesktop),
+	hid.Usage(hid.UsageMouse),
+	hid.Collection(hid.CollectionApplication),
+	hid.ReportID(reportMouse),
+	hid.Usage(hid.UsagePointer),
+	hid.Collection(hid.CollectionPhysical),
+	mouseButtonItems(),
+	hid.UsagePage(hid.PageGenericDesktop),
+	hid.Usage(hid.UsageX),
+	hid.Usage(hid.UsageY),
+	hid.Usage(hid.UsageWheel),
+	hid.LogicalMinimum(-127),
+	hid.LogicalMaximum(127),
+	hid.ReportSize(8),
+	hid.ReportCount(3),
+	hid.Input(hid.Data|hid.Var|hid.Rel),
+	hid.EndCollection(),
+	hid.EndCollection(),
+
+	hid.UsagePage(hid.PageGenericDesktop),
+	hid.Usage(hid.UsageMouse),
+	hid.Collection(hid.CollectionApplication),
+	hid.ReportID(reportPointer),
+	hid.Usage(hid.UsagePointer),
+	hid.Collection(hid.CollectionPhysical),
+	mouseButtonItems(),
+	hid.UsagePage(hid.PageGenericDesktop),
+	hid.Usage(hid.UsageX),
+	hid.Usage(hid.UsageY),
+	hid.LogicalMinimum(0),
+	hid.LogicalMaximum(pointerMax),
+	hid.ReportSize(16),
+	hid.ReportCount(2),
+	hid.Input(hid.Data|hid.Var|hid.Abs),
+	hid.EndCollection(),
+	hid.EndCollection(),
+
+	hid.UsagePage(hid.PageGenericDesktop),
+	hid.Usage(hid.UsageKeyboard),
+	hid.Collection(hid.CollectionApplication),
+	hid.ReportID(reportKeyboard),
+	hid.UsagePage(hid.PageKeyboard),
+	hid.UsageMinimum(hid.KeyLeftCtrl),
+	hid.UsageMaximum(hid.KeyRightGUI),
+	hid.LogicalMinimum(0),
+	hid.LogicalMaximum(1),
+	hid.ReportSize(1),
+	hid.ReportCount(8),
+	hid.Input(hid.Data|hid.Var|hid.Abs),
+	hid.ReportSize(8), // reserved
+	hid.ReportCount(1),
+	hid.Input(hid.Const|hid.Var|hid.Abs),
+	hid.UsagePage(hid.PageLED),
+	hid.UsageMinimum(1),
+	hid.UsageMaximum(5),
+	hid.ReportSize(1),
+	hid.ReportCount(5),
+	hid.Output(hid.Data|hid.Var|hid.Abs),
+	hid.ReportSize(3), // padding
+	hid.ReportCount(1),
+	hid.Output(hid.Const|hid.Var|hid.Abs),
+	hid.UsagePage(hid.PageKeyboard),
+	hid.UsageMinimum(0),
+	hid.UsageMaximum(0xff),
+	hid.LogicalMinimum(0),
+	hid.LogicalMaximum(0xff),
+	hid.ReportSize(8),
+	hid.ReportCount(6),
+	hid.Input(hid.Data|hid.Array|hid.Abs),
+	hid.EndCollection(),
+)
+
+func mouseButtonItems() []byte {
+	return hid.Append(
+		hid.UsagePage(hid.PageButton),
+		hid.UsageMinimum(1),
+		hid.UsageMaximum(mouseButtons),
+		hid.LogicalMinimum(0),
+		hid.LogicalMaximum(1),
+		hid.ReportSize(1),
+		hid.ReportCount(mouseButtons),
+		hid.Input(hid.Data|hid.Var|hid.Abs),
+		hid.ReportSize(8-mouseButtons), // padding
+		hid.ReportCount(1),
+		hid.Input(hid.Const|hid.Var|hid.Abs),
+	)
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/nobonobo/gamepad-emulator/hid"
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+func newTestPad(t *testing.T) (*JS, *fakeUSB) {
+	t.Helper()
+	usb := &fakeUSB{}
+	j, err := NewJS(usb, protocol.ProfileGamepad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return j, usb
+}
+
+// sent returns the reports sent with id, without the id byte.
+func (u *fakeUSB) sent(id byte) [][]byte {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	var reports [][]byte
+	for _, r := range u.reports {
+		if r[0] == id {
+			reports = append(reports, r[1:])
+		}
+	}
+	return reports
+}
+
+func TestMouseMove(t *testing.T) {
+	tests := []struct {
+		name          string
+		dx, dy, wheel int
+		want          [][]byte
+	}{
+		{"zero", 0, 0, 0, [][]byte{{0, 0, 0, 0}}},
+		{"small", -5, 7, 1, [][]byte{{0, 0xfb, 7, 1}}},
+		{"300 units", 300, 0, 0, [][]byte{{0, 127, 0, 0}, {0, 127, 0, 0}, {0, 46, 0, 0}}},
+		{"both axes", -130, 254, 0, [][]byte{{0, 0x81, 127, 0}, {0, 0xfd, 127, 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, usb := newTestPad(t)
+			if err := j.MouseMove(tt.dx, tt.dy, tt.wheel); err != nil {
+				t.Fatal(err)
+			}
+			got := usb.sent(reportMouse)
+			if len(got) != len(tt.want) {
+				t.Fatalf("%d reports, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if !bytes.Equal(got[i], tt.want[i]) {
+					t.Errorf("report %d = % x, want % x", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMouseMoveRange(t *testing.T) {
+	j, usb := newTestPad(t)
+	for _, d := range [][3]int{{maxMouseMove + 1, 0, 0}, {0, -maxMouseMove - 1, 0}, {0, 0, 1e15}} {
+		var e *ErrRange
+		if err := j.MouseMove(d[0], d[1], d[2]); !errors.As(err, &e) {
+			t.Errorf("MouseMove(%v) = %v, want a range error", d, err)
+		}
+	}
+	if n := len(usb.sent(reportMouse)); n != 0 {
+		t.Errorf("%d reports sent for rejected moves", n)
+	}
+	if err := j.MouseMove(maxMouseMove, -maxMouseMove, 0); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(usb.sent(reportMouse)); n != (maxMouseMove+126)/127 {
+		t.Errorf("%d reports for the largest move, want %d", n, (maxMouseMove+126)/127)
+	}
+}
+
+func TestReceiveOutputReport(t *testing.T) {
+	j, _ := newTestPad(t)
+	caps := []byte{reportKeyboard, 0x02}
+	vendor := []byte{1, 0xde, 0xad, 0, 0, 0, 0, 0, 0}
+	j.Receive(caps)
+	if b := j.OutputReport(); b != nil {
+		t.Errorf("keyboard LED report forwarded: % x", b)
+	}
+	j.Receive(vendor)
+	j.Receive(caps)
+	if b := j.OutputReport(); !bytes.Equal(b, vendor) {
+		t.Errorf("OutputReport = % x, want % x", b, vendor)
+	}
+	if b := j.OutputReport(); b != nil {
+		t.Errorf("second OutputReport = % x", b)
+	}
+}
+
+func TestMouseButton(t *testing.T) {
+	j, usb := newTestPad(t)
+	for _, step := range []struct {
+		index int
+		push  bool
+	}{{0, true}, {2, true}, {0, false}, {2, false}} {
+		if err := j.MouseButton(step.index, step.push); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := [][]byte{{0x01, 0, 0, 0}, {0x05, 0, 0, 0}, {0x04, 0, 0, 0}, {0, 0, 0, 0}}
+	got := usb.sent(reportMouse)
+	if len(got) != len(want) {
+		t.Fatalf("%d reports, want %d", len(got), len(want))
+	}
+	for i := range got {
+		if !bytes.Equal(got[i], want[i]) {
+			t.Errorf("report %d = % x, want % x", i, got[i], want[i])
+		}
+	}
+	if err := j.MouseButton(mouseButtons, true); err == nil {
+		t.Error("MouseButton accepted an index out of range")
+	}
+}
+
+func TestMouseAbs(t *testing.T) {
+	tests := []struct {
+		x, y float64
+		want []byte
+		ok   bool
+	}{
+		{0, 0, []byte{0, 0, 0, 0, 0}, true},
+		{1, 1, []byte{0, 0xff, 0x7f, 0xff, 0x7f}, true},
+		{0.5, 0.25, []byte{0, 0x00, 0x40, 0x00, 0x20}, true},
+		{-0.1, 0, nil, false},
+		{0, 1.5, nil, false},
+		{math.NaN(), 0, nil, false},
+	}
+	for _, tt := range tests {
+		j, usb := newTestPad(t)
+		err := j.MouseAbs(tt.x, tt.y)
+		if !tt.ok {
+			if !errors.Is(err, ErrPointer) || len(usb.sent(reportPointer)) != 0 {
+				t.Errorf("MouseAbs(%g, %g) = %v, want ErrPointer and no report", tt.x, tt.y, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := usb.last(reportPointer); !bytes.Equal(got, tt.want) {
+			t.Errorf("MouseAbs(%g, %g) = % x, want % x", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	key := func(c byte) func(j *JS) error { return func(j *JS) error { return j.KeyDown(int(c)) } }
+	up := func(c byte) func(j *JS) error { return func(j *JS) error { return j.KeyUp(int(c)) } }
+	shift := byte(hid.KeyLeftCtrl + 1)
+	tests := []struct {
+		name  string
+		steps []func(j *JS) error
+		want  []byte // last keyboard report
+	}{
+		{"key", []func(*JS) error{key(0x04)}, []byte{0, 0, 0x04, 0, 0, 0, 0, 0}},
+		{"repeat", []func(*JS) error{key(0x04), key(0x04)}, []byte{0, 0, 0x04, 0, 0, 0, 0, 0}},
+		{"modifier", []func(*JS) error{key(shift), key(0x05)}, []byte{hid.ModLeftShift, 0, 0x05, 0, 0, 0, 0, 0}},
+		{"right modifier", []func(*JS) error{key(hid.KeyRightGUI)}, []byte{hid.ModRightGUI, 0, 0, 0, 0, 0, 0, 0}},
+		{"release", []func(*JS) error{key(0x04), key(0x05), up(0x04)}, []byte{0, 0, 0, 0x05, 0, 0, 0, 0}},
+		{"reuse slot", []func(*JS) error{key(0x04), key(0x05), up(0x04), key(0x06)}, []byte{0, 0, 0x06, 0x05, 0, 0, 0, 0}},
+		{"release modifier", []func(*JS) error{key(shift), key(0x05), up(shift)}, []byte{0, 0, 0x05, 0, 0, 0, 0, 0}},
+		{"six keys", []func(*JS) error{key(4), key(5), key(6), key(7), key(8), key(9)}, []byte{0, 0, 4, 5, 6, 7, 8, 9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, usb := newTestPad(t)
+			for _, step := range tt.steps {
+				if err := step(j); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := usb.last(reportKeyboard); !bytes.Equal(got, tt.want) {
+				t.Errorf("report = % x, want % x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyErrors(t *testing.T) {
+	j, usb := newTestPad(t)
+	for code := 4; code < 10; code++ {
+		if err := j.KeyDown(code); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n := len(usb.sent(reportKeyboard))
+	if err := j.KeyDown(10); !errors.Is(err, ErrKeyboard) {
+		t.Errorf("seventh KeyDown = %v, want ErrKeyboard", err)
+	}
+	if len(usb.sent(reportKeyboard)) != n {
+		t.Error("rejected KeyDown sent a report")
+	}
+	for _, code := range []int{0, hid.KeyRightGUI + 1} {
+		var e *ErrRange
+		if err := j.KeyDown(code); !errors.As(err, &e) {
+			t.Errorf("KeyDown(%#x) = %v, want a range error", code, err)
+		}
+		if err := j.KeyUp(code); !errors.As(err, &e) {
+			t.Errorf("KeyUp(%#x) = %v, want a range error", code, err)
+		}
+	}
+}
+
+func TestTypeString(t *testing.T) {
+	j, usb := newTestPad(t)
+	if err := j.KeyDown(0x1d); err != nil { // z stays held while typing
+		t.Fatal(err)
+	}
+	if err := j.TypeString("aB!\n"); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i := 0; i < 8; i++ {
+		j.Tick(now)
+		j.Tick(now.Add(typeInterval / 2)) // too early for the next key
+		now = now.Add(typeInterval)
+	}
+	want := [][]byte{
+		{0, 0, 0x1d, 0, 0, 0, 0, 0},
+		{0, 0, 0x04, 0x1d, 0, 0, 0, 0},
+		{0, 0, 0x1d, 0, 0, 0, 0, 0},
+		{hid.ModLeftShift, 0, 0x05, 0x1d, 0, 0, 0, 0},
+		{0, 0, 0x1d, 0, 0, 0, 0, 0},
+		{hid.ModLeftShift, 0, 0x1e, 0x1d, 0, 0, 0, 0},
+		{0, 0, 0x1d, 0, 0, 0, 0, 0},
+		{0, 0, hid.KeyEnter, 0x1d, 0, 0, 0, 0},
+		{0, 0, 0x1d, 0, 0, 0, 0, 0},
+	}
+	got := usb.sent(reportKeyboard)
+	if len(got) != len(want) {
+		t.Fatalf("%d reports, want %d", len(got), len(want))
+	}
+	for i := range got {
+		if !bytes.Equal(got[i], want[i]) {
+			t.Errorf("report %d = % x, want % x", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTypeStringErrors(t *testing.T) {
+	j, _ := newTestPad(t)
+	if err := j.TypeString("caf\u00e9"); !errors.Is(err, ErrKeyboard) {
+		t.Errorf("TypeString of a non-US character = %v, want ErrKeyboard", err)
+	}
+	if len(j.comp.typing) != 0 {
+		t.Errorf("%d key events queued from a rejected string", len(j.comp.typing))
+	}
+	if err := j.TypeString(string(bytes.Repeat([]byte{'x'}, maxTyping))); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.TypeString("x"); !errors.Is(err, ErrKeyboard) {
+		t.Errorf("TypeString past the queue limit = %v, want ErrKeyboard", err)
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"encoding/hex"
+
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+// Suspender reports whether the USB bus is suspended by the PC.
+type Suspender interface {
+	Suspended() bool
+}
+
+// WatchUSB makes the firmware notify the host when the bus is suspended
+// or resumed.
+func (j *JoyStick) WatchUSB(s Suspender) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.usb = s
+	j.suspended = s.Suspended()
+}
+
+// pollEvents forwards device events that are not tied to a request.
+func (j *JoyStick) pollEvents() {
+	if j.usb != nil {
+		if suspended := j.usb.Suspended(); suspended != j.suspended {
+			j.suspended = suspended
+			if suspended {
+				j.notify(protocol.NotifyUSBSuspend, nil)
+			} else {
+				j.notify(protocol.NotifyUSBResume, nil)
+			}
+		}
+	}
+	// output reports start with the report id
+	if b := j.js.OutputReport(); len(b) > 0 {
+		j.notify(protocol.NotifyOutputReport, map[string]any{
+			"reportId": int(b[0]),
+			"data":     hex.EncodeToString(b[1:]),
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"bytes"
+
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+// splitMessages is a bufio.SplitFunc separating newline terminated JSON-RPC
+// lines from zero delimited binary frames. Frame tokens keep their leading
+// delimiter so that the caller can tell them apart.
+func splitMessages(data []byte, atEOF bool) (int, []byte, error) {
+	if len(data) == 0 {
+		return 0, nil, nil
+	}
+	if data[0] == protocol.FrameDelimiter {
+		if i := bytes.IndexByte(data[1:], protocol.FrameDelimiter); i >= 0 {
+			return i + 2, data[:i+1], nil
+		}
+		if atEOF {
+			return len(data), nil, nil
+		}
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\n\x00"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		return i, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+func (j *JoyStick) handleFrame(body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	if j.encoding != protocol.EncodingBinary {
+		j.frameStats.Rejected++
+		return
+	}
+	f, err := protocol.ParseFrame(body)
+	if err != nil {
+		j.frameStats.Errors++
+		return
+	}
+	if j.frameStats.Frames > 0 {
+		j.frameStats.Lost += int(f.Seq - j.lastSeq - 1)
+	}
+	j.lastSeq = f.Seq
+	j.frameStats.Frames++
+	js, payload := j.js, f.Payload
+	switch f.Type {
+	case protocol.FrameSetState, protocol.FrameSetStateSend:
+	case protocol.FrameSetPadState, protocol.FrameSetPadStateSend:
+		if len(payload) == 0 || int(payload[0]) >= len(j.pads) {
+			j.frameStats.Errors++
+			return
+		}
+		js, payload = j.pads[payload[0]], payload[1:]
+	default:
+		j.frameStats.Errors++
+		return
+	}
+	var s protocol.GamepadState
+	if err := s.UnmarshalBinary(payload); err != nil {
+		j.frameStats.Errors++
+		return
+	}
+	if err := js.SetState(s); err != nil {
+		j.frameStats.Errors++
+		return
+	}
+	if f.Type == protocol.FrameSetStateSend || f.Type == protocol.FrameSetPadStateSend {
+		js.SendState()
+	}
+}
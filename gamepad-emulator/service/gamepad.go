@@ -1,11 +1,11 @@
 package service
 
 import (
-	"encoding/binary"
-	"machine/usb"
+	"fmt"
+	"sync/atomic"
+	"time"
 
-	"machine/usb/descriptor"
-	"machine/usb/hid/joystick"
+	"github.com/nobonobo/gamepad-emulator/protocol"
 )
 
 type SendReporter interface {
@@ -13,150 +13,403 @@ type SendReporter interface {
 }
 
 type JoySticker interface {
-	Button(index int) bool
-	SetButton(index int, push bool)
-	Hat(index int) joystick.HatDirection
-	SetHat(index int, dir joystick.HatDirection)
-	Axis(index int) int
-	SetAxis(index int, v int)
+	Button(index int) (bool, error)
+	SetButton(index int, push bool) error
+	ButtonMode(index int) (protocol.ButtonMode, error)
+	SetButtonMode(index int, mode protocol.ButtonMode) error
+	PressFor(index int, d time.Duration) error
+	Hat(index int) (HatDirection, error)
+	SetHat(index int, dir HatDirection) error
+	Axis(index int) (int, error)
+	SetAxis(index int, v int) error
+	Trigger(index int) (int, error)
+	SetTrigger(index int, v int) error
+	Layout() protocol.Layout
+	Profile() string
+	State() protocol.GamepadState
+	SetState(s protocol.GamepadState) error
+	Neutral()
+	AddOverlay(o Overlay)
 	SendState()
+	MouseMove(dx, dy, wheel int) error
+	MouseButton(index int, push bool) error
+	MouseAbs(x, y float64) error
+	KeyDown(code int) error
+	KeyUp(code int) error
+	TypeString(text string) error
+	Descriptor() []byte
+	LastReport() (reportID int, b []byte)
+	OutputReport() []byte
+	Interpolation() protocol.Interpolation
+	SetInterpolation(v protocol.Interpolation) error
+	Reporting() protocol.Reporting
+	SetReporting(v protocol.Reporting) error
+	ReportStats() protocol.ReportStats
+	ReportInterval() time.Duration
+	Tick(now time.Time)
 }
 
-var js JoySticker
+// HatDirection matches machine/usb/hid/HatDirection.
+type HatDirection uint8
+
+const (
+	HatUp HatDirection = iota
+	HatRightUp
+	HatRight
+	HatRightDown
+	HatDown
+	HatLeftDown
+	HatLeft
+	HatLeftUp
+	HatCenter
+)
+
+// Axis and trigger ranges shared by the HID descriptor, the report packing
+// and the RPC interface.
+const (
+	axisMin    = -32767
+	axisMax    = 32767
+	triggerMin = 0
+	triggerMax = 255
+
+	outputReportSize = 8 // vendor defined output report from the PC
+)
+
+// Overlay contributes device-side input, merged over the host state each
+// time a report is built.
+type Overlay interface {
+	Apply(s *protocol.GamepadState)
+}
 
 type JS struct {
 	js       SendReporter
-	buf      [13]byte
-	axis     [4]int16
-	triggers [2]uint8
-	buttons  [10]bool
-	hat      uint8
+	id       byte // report id
+	profile  *profile
+	desc     []byte
+	overlays []Overlay
+	out      protocol.GamepadState
+	buf      []byte
+	axis     []int16
+	triggers []uint8
+	buttons  []bool
+	modes    []buttonMode
+	hats     []uint8
+	interp   interp
+	rep      reporting
+	comp     composite
+
+	// output report mailbox filled by the USB interrupt
+	rxFull atomic.Bool
+	rxLen  int
+	rxBuf  [64]byte
+}
+
+// ErrRange is returned for indexes and values outside of the layout.
+type ErrRange struct {
+	Name  string
+	Value int
+	Min   int
+	Max   int
+}
+
+func (e *ErrRange) Error() string {
+	return fmt.Sprintf("%s out of range: %d (want %d..%d)", e.Name, e.Value, e.Min, e.Max)
+}
+
+func checkRange(name string, v, min, max int) error {
+	if v < min || v > max {
+		return &ErrRange{Name: name, Value: v, Min: min, Max: max}
+	}
+	return nil
 }
 
-func (j *JS) Button(index int) bool {
-	return j.buttons[index]
+func (j *JS) Button(index int) (bool, error) {
+	if err := checkRange("button index", index, 0, len(j.buttons)-1); err != nil {
+		return false, err
+	}
+	return j.buttons[index], nil
 }
 
-func (j *JS) SetButton(index int, push bool) {
+func (j *JS) SetButton(index int, push bool) error {
+	if err := checkRange("button index", index, 0, len(j.buttons)-1); err != nil {
+		return err
+	}
 	j.buttons[index] = push
+	j.evalButton(index, time.Now())
+	j.changed()
+	return nil
+}
+
+func (j *JS) Hat(index int) (HatDirection, error) {
+	if err := checkRange("hat index", index, 0, len(j.hats)-1); err != nil {
+		return HatCenter, err
+	}
+	return HatDirection(j.hats[index]), nil
+}
+
+func (j *JS) SetHat(index int, dir HatDirection) error {
+	if err := checkRange("hat index", index, 0, len(j.hats)-1); err != nil {
+		return err
+	}
+	if err := checkRange("hat direction", int(dir), int(HatUp), int(HatCenter)); err != nil {
+		return err
+	}
+	j.hats[index] = uint8(dir)
+	j.changed()
+	return nil
 }
 
-func (j *JS) Hat(index int) joystick.HatDirection {
-	return joystick.HatDirection(j.hat)
+func (j *JS) Axis(index int) (int, error) {
+	if err := checkRange("axis index", index, 0, len(j.axis)-1); err != nil {
+		return 0, err
+	}
+	return int(j.axis[index]), nil
 }
 
-func (j *JS) SetHat(index int, dir joystick.HatDirection) {
-	j.hat = uint8(dir)
+// SetAxis saturates v to the axis range.
+func (j *JS) SetAxis(index int, v int) error {
+	if err := checkRange("axis index", index, 0, len(j.axis)-1); err != nil {
+		return err
+	}
+	j.axis[index] = int16(min(max(v, axisMin), axisMax))
+	j.changed()
+	return nil
 }
 
-func (j *JS) Axis(index int) int {
-	return int(j.axis[index])
+func (j *JS) Trigger(index int) (int, error) {
+	if err := checkRange("trigger index", index, 0, len(j.triggers)-1); err != nil {
+		return 0, err
+	}
+	return int(j.triggers[index]), nil
 }
 
-func (j *JS) SetAxis(index int, v int) {
-	j.axis[index] = int16(v)
+// SetTrigger saturates v to the trigger range.
+func (j *JS) SetTrigger(index int, v int) error {
+	if err := checkRange("trigger index", index, 0, len(j.triggers)-1); err != nil {
+		return err
+	}
+	j.triggers[index] = uint8(min(max(v, triggerMin), triggerMax))
+	j.changed()
+	return nil
 }
 
-func (j *JS) SendState() {
-	binary.LittleEndian.PutUint16(j.buf[0:2], uint16(j.axis[0]))
-	binary.LittleEndian.PutUint16(j.buf[2:4], uint16(j.axis[1]))
-	binary.LittleEndian.PutUint16(j.buf[4:6], uint16(j.axis[2]))
-	binary.LittleEndian.PutUint16(j.buf[6:8], uint16(j.axis[3]))
-	j.buf[8] = j.triggers[0]
-	j.buf[9] = j.triggers[1]
-	j.buf[10] = 0
-	j.buf[11] = 0
-	for i, v := range j.buttons[:8] {
-		if v {
-			j.buf[10] |= 1 << i
+func (j *JS) Layout() protocol.Layout {
+	return j.profile.layout()
+}
+
+// Profile returns the name of the profile the device enumerated as.
+func (j *JS) Profile() string {
+	return j.profile.name
+}
+
+// State returns the state set by the host, without overlays.
+func (j *JS) State() protocol.GamepadState {
+	var s protocol.GamepadState
+	j.stateInto(&s)
+	return s
+}
+
+// stateInto copies the host state into s, reusing its slices.
+func (j *JS) stateInto(s *protocol.GamepadState) {
+	s.Axes = resize(s.Axes, len(j.axis))
+	s.Triggers = resize(s.Triggers, len(j.triggers))
+	s.Buttons = resize(s.Buttons, len(j.buttons))
+	s.Hats = resize(s.Hats, len(j.hats))
+	for i, v := range j.axis {
+		s.Axes[i] = int(v)
+	}
+	for i, v := range j.triggers {
+		s.Triggers[i] = int(v)
+	}
+	copy(s.Buttons, j.buttons)
+	for i, v := range j.hats {
+		s.Hats[i] = int(v)
+	}
+}
+
+func resize[T any](s []T, n int) []T {
+	if cap(s) < n {
+		return make([]T, n)
+	}
+	return s[:n]
+}
+
+// SetState replaces the whole state; nothing is applied if any field is
+// malformed.
+func (j *JS) SetState(s protocol.GamepadState) error {
+	if len(s.Axes) != len(j.axis) {
+		return fmt.Errorf("invalid state: want %d axes, got %d", len(j.axis), len(s.Axes))
+	}
+	if len(s.Triggers) != len(j.triggers) {
+		return fmt.Errorf("invalid state: want %d triggers, got %d", len(j.triggers), len(s.Triggers))
+	}
+	if len(s.Buttons) != len(j.buttons) {
+		return fmt.Errorf("invalid state: want %d buttons, got %d", len(j.buttons), len(s.Buttons))
+	}
+	for _, v := range s.Triggers {
+		if err := checkRange("trigger", v, triggerMin, triggerMax); err != nil {
+			return err
+		}
+	}
+	if len(s.Hats) != len(j.hats) {
+		return fmt.Errorf("invalid state: want %d hats, got %d", len(j.hats), len(s.Hats))
+	}
+	for _, v := range s.Hats {
+		if err := checkRange("hat direction", v, int(HatUp), int(HatCenter)); err != nil {
+			return err
 		}
 	}
-	for i, v := range j.buttons[8:] {
+	for i, v := range s.Axes {
+		j.axis[i] = int16(min(max(v, axisMin), axisMax))
+	}
+	for i, v := range s.Triggers {
+		j.triggers[i] = uint8(v)
+	}
+	copy(j.buttons, s.Buttons)
+	now := time.Now()
+	for i := range j.buttons {
+		j.evalButton(i, now)
+	}
+	for i, v := range s.Hats {
+		j.hats[i] = uint8(v)
+	}
+	j.changed()
+	return nil
+}
+
+// Neutral centers all axes and hats and releases buttons and triggers.
+func (j *JS) Neutral() {
+	clear(j.axis)
+	clear(j.triggers)
+	clear(j.buttons)
+	now := time.Now()
+	for i := range j.modes {
+		j.modes[i].until = time.Time{}
+		j.modes[i].latched = false
+		j.evalButton(i, now)
+	}
+	j.snapAxes()
+	j.changed()
+	j.releaseComposite()
+	for i := range j.hats {
+		j.hats[i] = uint8(HatCenter)
+	}
+}
+
+func (j *JS) AddOverlay(o Overlay) {
+	j.overlays = append(j.overlays, o)
+}
+
+// build packs the report SendState sends into j.buf.
+func (j *JS) build(now time.Time) {
+	s := &j.out
+	j.stateInto(s)
+	for i := range j.modes {
+		s.Buttons[i] = j.pressed(i, now)
+		j.modes[i].out = s.Buttons[i]
+	}
+	for i := range j.axis {
+		s.Axes[i] = j.axisOut(i)
+	}
+	for _, o := range j.overlays {
+		o.Apply(s)
+	}
+	p := j.profile
+	for i, v := range s.Axes {
+		p.axes.Put(j.buf, i, min(max(v, axisMin), axisMax))
+	}
+	for i, v := range s.Triggers {
+		p.triggers.Put(j.buf, i, min(max(v, triggerMin), triggerMax))
+	}
+	for i, v := range s.Buttons {
+		b := 0
 		if v {
-			j.buf[11] |= 1 << i
+			b = 1
 		}
+		p.buttons.Put(j.buf, i, b)
 	}
-	j.buf[12] = j.hat
-	j.js.SendReport(1, j.buf[:])
-}
-
-func init() {
-	usb.VendorID = 0x2786
-	usb.ProductID = 0x000a
-	usb.Product = "Gamepad Emulator"
-	usb.Manufacturer = "Switch Science"
-
-	js = &JS{
-		js: joystick.UseSettings(joystick.Definitions{
-			ReportID:     1,
-			ButtonCnt:    10,
-			HatSwitchCnt: 1,
-			AxisDefs: []joystick.Constraint{
-				{MinIn: -32767, MaxIn: 32767, MinOut: -32767, MaxOut: 32767},
-				{MinIn: -32767, MaxIn: 32767, MinOut: -32767, MaxOut: 32767},
-				{MinIn: -32767, MaxIn: 32767, MinOut: -32767, MaxOut: 32767},
-				{MinIn: -32767, MaxIn: 32767, MinOut: -32767, MaxOut: 32767},
-				{MinIn: 0, MaxIn: 1023, MinOut: 0, MaxOut: 1023},
-				{MinIn: 0, MaxIn: 1023, MinOut: 0, MaxOut: 1023},
-			},
-		}, nil, nil, desc),
-	}
-}
-
-var desc = descriptor.Append([][]byte{
-	descriptor.HIDUsagePageGenericDesktop,
-	descriptor.HIDUsageDesktopGamepad,
-
-	descriptor.HIDCollectionApplication,
-
-	descriptor.HIDReportID(1),
-	descriptor.HIDUsageDesktopPointer,
-
-	descriptor.HIDCollectionPhysical,
-
-	descriptor.HIDUsageDesktopX,
-	descriptor.HIDUsageDesktopY,
-	descriptor.HIDUsageDesktopRx,
-	descriptor.HIDUsageDesktopRy,
-	descriptor.HIDLogicalMinimum(-8191),
-	descriptor.HIDLogicalMaximum(8191),
-	descriptor.HIDReportSize(16),
-	descriptor.HIDReportCount(4),
-	descriptor.HIDInputConstVarAbs,
-	descriptor.HIDUsagePageGenericDesktop,
-	descriptor.HIDUsageDesktopZ,
-	descriptor.HIDUsageDesktopRz,
-	descriptor.HIDLogicalMinimum(0),
-	descriptor.HIDLogicalMaximum(255),
-	descriptor.HIDReportSize(8),
-	descriptor.HIDReportCount(2),
-	descriptor.HIDInputConstVarAbs,
-	descriptor.HIDUsagePageButton,
-	descriptor.HIDUsageMinimum(1),
-	descriptor.HIDUsageMaximum(10),
-	descriptor.HIDLogicalMinimum(0),
-	descriptor.HIDLogicalMaximum(1),
-	descriptor.HIDReportSize(1),
-	descriptor.HIDReportCount(10),
-	descriptor.HIDInputConstVarAbs,
-	[]byte{0x75, 0x06}, // Padding
-	descriptor.HIDReportCount(1),
-	descriptor.HIDInputConstVarAbs,
-	descriptor.HIDUsagePageGenericDesktop,
-	descriptor.HIDUsageDesktopHatSwitch,
-	descriptor.HIDLogicalMinimum(0),
-	descriptor.HIDLogicalMaximum(7),
-	descriptor.HIDPhysicalMinimum(0),
-	descriptor.HIDPhysicalMaximum(315),
-	descriptor.HIDUnit(0x14), // UNIT (Eng Rotation: Centimeter)
-	descriptor.HIDReportSize(4),
-	descriptor.HIDReportCount(1),
-	descriptor.HIDInputDataVarAbs,
-	[]byte{0x75, 0x04}, // Padding
-	descriptor.HIDReportCount(1),
-	descriptor.HIDInputConstVarAbs,
-
-	descriptor.HIDCollectionEnd,
-
-	descriptor.HIDCollectionEnd,
-})
+	for i, v := range s.Hats {
+		p.hats.Put(j.buf, i, v)
+	}
+}
+
+// Descriptor returns the HID report descriptor matching SendState.
+func (j *JS) Descriptor() []byte {
+	return j.desc
+}
+
+// LastReport returns the report most recently sent.
+func (j *JS) LastReport() (int, []byte) {
+	return int(j.id), j.rep.prev
+}
+
+// Receive stores an output report from the PC until OutputReport takes it.
+// It runs in interrupt context and drops reports while one is pending.
+// Only the vendor report of the pad is kept; keyboard LED reports are
+// discarded so that they cannot displace it.
+func (j *JS) Receive(b []byte) {
+	if len(b) == 0 || b[0] != j.id || j.rxFull.Load() {
+		return
+	}
+	j.rxLen = copy(j.rxBuf[:], b)
+	j.rxFull.Store(true)
+}
+
+// OutputReport returns the pending output report, or nil.
+func (j *JS) OutputReport() []byte {
+	if !j.rxFull.Load() {
+		return nil
+	}
+	b := append([]byte(nil), j.rxBuf[:j.rxLen]...)
+	j.rxFull.Store(false)
+	return b
+}
+
+// NewJS returns the state of a gamepad laid out by the named profile.
+func NewJS(r SendReporter, name string) (*JS, error) {
+	pads, err := NewPads(r, name, 1)
+	if err != nil {
+		return nil, err
+	}
+	return pads[0], nil
+}
+
+// NewPads returns the states of n gamepads laid out by the named profile,
+// sharing one descriptor with a report id per pad. Mouse, keyboard and the
+// output report are served by the first pad.
+func NewPads(r SendReporter, name string, n int) ([]*JS, error) {
+	p, err := findProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRange("pads", n, 1, maxPads); err != nil {
+		return nil, err
+	}
+	desc := p.descriptor(n)
+	pads := make([]*JS, n)
+	for i := range pads {
+		j := &JS{
+			js:       r,
+			id:       byte(padReportID(i)),
+			profile:  p,
+			desc:     desc,
+			buf:      make([]byte, p.report.Size()),
+			axis:     make([]int16, p.axes.Count),
+			triggers: make([]uint8, p.triggers.Count),
+			buttons:  make([]bool, p.buttons.Count),
+			modes:    make([]buttonMode, p.buttons.Count),
+			hats:     make([]uint8, p.hats.Count),
+		}
+		for k := range j.modes {
+			j.modes[k].mode = protocol.ButtonNormal
+		}
+		j.interp.mode = protocol.InterpolateOff
+		j.interp.pos = make([]float64, p.axes.Count)
+		j.interp.vel = make([]float64, p.axes.Count)
+		j.rep.poll = usbPollInterval
+		j.rep.prev = make([]byte, p.report.Size())
+		j.Neutral()
+		pads[i] = j
+	}
+	return pads, nil
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nobonobo/gamepad-emulator/hid"
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+func TestReportRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		pads    int
+		pad     int
+		set     func(j *JS) error
+		want    map[string]int
+	}{
+		{
+			name:    "gamepad neutral",
+			profile: protocol.ProfileGamepad,
+			pads:    1,
+			set:     func(j *JS) error { return nil },
+			want: map[string]int{
+				"X": 0, "Y": 0, "Rx": 0, "Ry": 0, "Z": 0, "Rz": 0,
+				"Button 1": 0, "Button 10": 0, "Hat Switch": 8, "Hat Switch 2": 8,
+			},
+		},
+		{
+			name:    "gamepad inputs",
+			profile: protocol.ProfileGamepad,
+			pads:    1,
+			set: func(j *JS) error {
+				return firstErr(
+					j.SetAxis(0, -32767),
+					j.SetAxis(3, 1234),
+					j.SetTrigger(1, 255),
+					j.SetButton(0, true),
+					j.SetButton(9, true),
+					j.SetHat(1, HatLeftUp),
+				)
+			},
+			want: map[string]int{
+				"X": -32767, "Ry": 1234, "Rz": 255, "Z": 0,
+				"Button 1": 1, "Button 2": 0, "Button 10": 1,
+				"Hat Switch": 8, "Hat Switch 2": 7,
+			},
+		},
+		{
+			name:    "flight stick",
+			profile: protocol.ProfileFlightStick,
+			pads:    1,
+			set: func(j *JS) error {
+				return firstErr(
+					j.SetAxis(2, -500),
+					j.SetTrigger(0, 128),
+					j.SetButton(31, true),
+					j.SetHat(0, HatDown),
+				)
+			},
+			want: map[string]int{"Rz": -500, "Slider": 128, "Button 32": 1, "Button 31": 0, "Hat Switch": 4},
+		},
+		{
+			name:    "wheel",
+			profile: protocol.ProfileWheel,
+			pads:    1,
+			set: func(j *JS) error {
+				return firstErr(
+					j.SetAxis(0, 32767),
+					j.SetTrigger(2, 40),
+					j.SetButton(13, true),
+				)
+			},
+			want: map[string]int{"X": 32767, "Z": 0, "Slider": 40, "Button 14": 1, "Hat Switch": 8},
+		},
+		{
+			name:    "second pad",
+			profile: protocol.ProfileGamepad,
+			pads:    3,
+			pad:     1,
+			set: func(j *JS) error {
+				return firstErr(
+					j.SetAxis(1, 77),
+					j.SetButton(2, true),
+					j.SetHat(0, HatRight),
+				)
+			},
+			want: map[string]int{"Y": 77, "Button 3": 1, "Hat Switch": 2, "Hat Switch 2": 8},
+		},
+		{
+			name:    "last pad",
+			profile: protocol.ProfileWheel,
+			pads:    4,
+			pad:     3,
+			set:     func(j *JS) error { return j.SetAxis(0, -1) },
+			want:    map[string]int{"X": -1, "Button 1": 0, "Hat Switch": 8},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usb := &fakeUSB{}
+			pads, err := NewPads(usb, tt.profile, tt.pads)
+			if err != nil {
+				t.Fatal(err)
+			}
+			j := pads[tt.pad]
+			if err := tt.set(j); err != nil {
+				t.Fatal(err)
+			}
+			j.SendState()
+			id, report := j.LastReport()
+			if id != padReportID(tt.pad) {
+				t.Errorf("report id = %d, want %d", id, padReportID(tt.pad))
+			}
+			if sent := usb.last(byte(id)); !bytes.Equal(sent, report) {
+				t.Errorf("sent % x, LastReport % x", sent, report)
+			}
+			d, err := hid.Parse(j.Descriptor())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := d.Decode(id, report)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range tt.want {
+				if v, ok := got[name]; !ok || v != want {
+					t.Errorf("%s = %d (present %v), want %d", name, v, ok, want)
+				}
+			}
+		})
+	}
+}
+
+// TestPadsDecodeAlike checks that every pad decodes to the same field names.
+func TestPadsDecodeAlike(t *testing.T) {
+	pads, err := NewPads(&fakeUSB{}, protocol.ProfileGamepad, maxPads)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := hid.Parse(pads[0].Descriptor())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first map[string]int
+	for i, j := range pads {
+		id, report := j.LastReport()
+		got, err := d.Decode(id, report)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			first = got
+			continue
+		}
+		for name := range first {
+			if _, ok := got[name]; !ok {
+				t.Errorf("pad %d has no field %q", i, name)
+			}
+		}
+		if len(got) != len(first) {
+			t.Errorf("pad %d has %d fields, want %d", i, len(got), len(first))
+		}
+	}
+}
+
+func TestNewPadsRange(t *testing.T) {
+	for _, n := range []int{0, maxPads + 1} {
+		if _, err := NewPads(nil, protocol.ProfileGamepad, n); err == nil {
+			t.Errorf("NewPads(%d) succeeded", n)
+		}
+	}
+	if _, err := NewJS(nil, "joystick"); err == nil {
+		t.Error("NewJS accepted an unknown profile")
+	}
+}
+
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+const (
+	defaultLinearRate  = 4 * axisMax // full deflection in 250ms
+	defaultDampedRate  = 30.0
+	defaultInterpEvery = 4 * time.Millisecond
+	maxInterpStep      = 100 * time.Millisecond
+)
+
+var ErrInterpolation = errors.New("invalid interpolation")
+
+// interp moves the reported axes toward the host's values.
+type interp struct {
+	mode     string
+	rate     float64
+	interval time.Duration
+	last     time.Time
+	pos      []float64
+	vel      []float64
+}
+
+func (j *JS) Interpolation() protocol.Interpolation {
+	p := &j.interp
+	return protocol.Interpolation{
+		Mode:     p.mode,
+		Rate:     p.rate,
+		Interval: int(p.interval / time.Millisecond),
+	}
+}
+
+// SetInterpolation changes the axis interpolation; zero Rate and Interval
+// select the defaults. Axes continue from where they are reported now.
+func (j *JS) SetInterpolation(v protocol.Interpolation) error {
+	p := &j.interp
+	switch v.Mode {
+	case protocol.InterpolateOff:
+		v.Rate, v.Interval = 0, 0
+	case protocol.InterpolateLinear:
+		if v.Rate == 0 {
+			v.Rate = defaultLinearRate
+		}
+	case protocol.InterpolateDamped:
+		if v.Rate == 0 {
+			v.Rate = defaultDampedRate
+		}
+	default:
+		return fmt.Errorf("%w: unknown mode %q", ErrInterpolation, v.Mode)
+	}
+	if v.Rate < 0 || v.Interval < 0 || v.Interval > 1000 {
+		return fmt.Errorf("%w: rate %g, interval %d", ErrInterpolation, v.Rate, v.Interval)
+	}
+	if p.mode == protocol.InterpolateOff {
+		for i, a := range j.axis {
+			p.pos[i] = float64(a)
+			p.vel[i] = 0
+		}
+	}
+	p.mode = v.Mode
+	p.rate = v.Rate
+	p.interval = time.Duration(v.Interval) * time.Millisecond
+	if p.interval == 0 && v.Mode != protocol.InterpolateOff {
+		p.interval = defaultInterpEvery
+	}
+	p.last = time.Now()
+	return nil
+}
+
+// axisOut returns the reported value of an axis.
+func (j *JS) axisOut(index int) int {
+	if j.interp.mode == protocol.InterpolateOff {
+		return int(j.axis[index])
+	}
+	return int(math.Round(j.interp.pos[index]))
+}
+
+// snapAxes jumps the interpolated axes to the host's values.
+func (j *JS) snapAxes() {
+	for i, a := range j.axis {
+		j.interp.pos[i] = float64(a)
+		j.interp.vel[i] = 0
+	}
+}
+
+// stepAxes advances the interpolation to now and reports whether a
+// reported axis changed.
+func (j *JS) stepAxes(now time.Time) bool {
+	p := &j.interp
+	if p.mode == protocol.InterpolateOff {
+		return false
+	}
+	dt := min(now.Sub(p.last), maxInterpStep).Seconds()
+	p.last = now
+	changed := false
+	for i, a := range j.axis {
+		before := j.axisOut(i)
+		target := float64(a)
+		switch p.mode {
+		case protocol.InterpolateLinear:
+			step := p.rate * dt
+			d := target - p.pos[i]
+			p.pos[i] += min(max(d, -step), step)
+		case protocol.InterpolateDamped:
+			// exact critically damped spring toward target
+			w := p.rate
+			x := p.pos[i] - target
+			c := p.vel[i] + w*x
+			e := math.Exp(-w * dt)
+			p.pos[i] = target + (x+c*dt)*e
+			p.vel[i] = (p.vel[i] - w*c*dt) * e
+			if math.Abs(p.pos[i]-target) < 0.5 && math.Abs(p.vel[i]) < 1 {
+				p.pos[i] = target
+				p.vel[i] = 0
+			}
+		}
+		if j.axisOut(i) != before {
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"time"
+
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+const (
+	heartbeatPeriod = time.Second
+	heartbeatFlash  = 50 * time.Millisecond
+	activityFlash   = 20 * time.Millisecond
+	maxLEDPattern   = 16
+)
+
+// Output is a digital output such as machine.Pin.
+type Output interface {
+	Set(bool)
+}
+
+type led struct {
+	out     Output
+	def     string
+	mode    string
+	pattern []time.Duration // alternating on and off times, starting with on
+	repeat  bool
+	start   time.Time
+	level   bool
+}
+
+// AddLED registers a status LED showing the firmware function def
+// (protocol.LEDHeartbeat, LEDActivity or LEDWatchdog) until the host takes
+// it over with SetLED or LEDPattern.
+func (j *JoyStick) AddLED(out Output, def string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	l := &led{out: out, def: def, mode: protocol.LEDDefault}
+	out.Set(false)
+	j.leds = append(j.leds, l)
+}
+
+func (l *led) set(mode string, pattern []time.Duration, repeat bool, now time.Time) {
+	l.mode = mode
+	l.pattern = pattern
+	l.repeat = repeat
+	l.start = now
+}
+
+// patternLevel reports whether the pattern is lit at elapsed and whether it
+// has finished.
+func (l *led) patternLevel(elapsed time.Duration) (on, done bool) {
+	var total time.Duration
+	for _, d := range l.pattern {
+		total += d
+	}
+	if total <= 0 {
+		return false, true
+	}
+	if elapsed >= total {
+		if !l.repeat {
+			return false, true
+		}
+		elapsed %= total
+	}
+	for i, d := range l.pattern {
+		if elapsed < d {
+			return i%2 == 0, false
+		}
+		elapsed -= d
+	}
+	return false, false
+}
+
+func (j *JoyStick) defaultLevel(def string, now time.Time) bool {
+	switch def {
+	case protocol.LEDHeartbeat:
+		return time.Duration(now.UnixNano())%heartbeatPeriod < heartbeatFlash
+	case protocol.LEDActivity:
+		return now.Sub(j.watchdog.last) < activityFlash
+	case protocol.LEDWatchdog:
+		return j.watchdog.tripped
+	}
+	return false
+}
+
+func (j *JoyStick) updateLEDs(now time.Time) {
+	for _, l := range j.leds {
+		var on bool
+		switch l.mode {
+		case protocol.LEDOn:
+			on = true
+		case protocol.LEDPattern:
+			var done bool
+			if on, done = l.patternLevel(now.Sub(l.start)); done && !l.repeat {
+				l.set(protocol.LEDDefault, nil, false, now)
+				on = j.defaultLevel(l.def, now)
+			}
+		case protocol.LEDDefault:
+			on = j.defaultLevel(l.def, now)
+		}
+		if on != l.level {
+			l.level = on
+			l.out.Set(on)
+		}
+	}
+}
+
+func (j *JoyStick) ledStatus() []protocol.LEDStatus {
+	status := make([]protocol.LEDStatus, len(j.leds))
+	for i, l := range j.leds {
+		status[i] = protocol.LEDStatus{Mode: l.mode, Default: l.def}
+	}
+	return status
+}
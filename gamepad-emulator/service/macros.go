@@ -0,0 +1,316 @@
+package service
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+const (
+	maxMacros     = 16
+	maxMacroSteps = 64
+)
+
+// macroStep changes some inputs and holds them for d. Changes persist
+// until a later step changes them again or the macro ends.
+type macroStep struct {
+	axes     map[int]int
+	triggers map[int]int
+	buttons  map[int]bool
+	hats     map[int]int
+	d        time.Duration
+}
+
+type macro struct {
+	steps []macroStep
+}
+
+func (m *macro) duration() time.Duration {
+	var d time.Duration
+	for _, s := range m.steps {
+		d += s.d
+	}
+	return d
+}
+
+// macroRun is a running macro and the inputs it currently holds.
+type macroRun struct {
+	name     string
+	pad      int
+	m        *macro
+	step     int
+	next     time.Time // end of the current step
+	repeat   int
+	axes     map[int]int
+	triggers map[int]int
+	buttons  map[int]bool
+	hats     map[int]int
+}
+
+func (r *macroRun) begin(now time.Time) {
+	r.step = 0
+	r.next = now
+	r.axes = map[int]int{}
+	r.triggers = map[int]int{}
+	r.buttons = map[int]bool{}
+	r.hats = map[int]int{}
+	r.enter()
+}
+
+// enter applies the current step and schedules its end.
+func (r *macroRun) enter() {
+	s := &r.m.steps[r.step]
+	for i, v := range s.axes {
+		r.axes[i] = v
+	}
+	for i, v := range s.triggers {
+		r.triggers[i] = v
+	}
+	for i, v := range s.buttons {
+		r.buttons[i] = v
+	}
+	for i, v := range s.hats {
+		r.hats[i] = v
+	}
+	r.next = r.next.Add(s.d)
+}
+
+// macros runs uploaded macros, each on one pad.
+type macros struct {
+	defs    map[string]*macro
+	running []*macroRun
+}
+
+// padMacros merges the inputs of the macros running on one pad over the
+// host state: axes, triggers and hats they set are overridden, buttons they
+// press are pressed.
+type padMacros struct {
+	ms  *macros
+	pad int
+}
+
+func (p padMacros) Apply(s *protocol.GamepadState) {
+	for _, r := range p.ms.running {
+		if r.pad != p.pad {
+			continue
+		}
+		for i, v := range r.axes {
+			s.Axes[i] = v
+		}
+		for i, v := range r.triggers {
+			s.Triggers[i] = v
+		}
+		for i, v := range r.buttons {
+			s.Buttons[i] = s.Buttons[i] || v
+		}
+		for i, v := range r.hats {
+			s.Hats[i] = v
+		}
+	}
+}
+
+func (ms *macros) find(name string) int {
+	for i, r := range ms.running {
+		if r.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// start runs macro name on pad repeat more times after the first,
+// restarting it if it is already running.
+func (ms *macros) start(name string, pad, repeat int, now time.Time) bool {
+	m, ok := ms.defs[name]
+	if !ok {
+		return false
+	}
+	r := &macroRun{name: name, pad: pad, m: m, repeat: repeat}
+	if i := ms.find(name); i >= 0 {
+		ms.running[i] = r
+	} else {
+		ms.running = append(ms.running, r)
+	}
+	r.begin(now)
+	return true
+}
+
+func (ms *macros) stop(i int) {
+	ms.running = append(ms.running[:i], ms.running[i+1:]...)
+}
+
+// advance moves running macros to the step due at now. It reports whether
+// any input changed and calls done for finished macros.
+func (ms *macros) advance(now time.Time, done func(r *macroRun)) bool {
+	changed := false
+	for i := 0; i < len(ms.running); i++ {
+		r := ms.running[i]
+		for !now.Before(r.next) {
+			changed = true
+			r.step++
+			if r.step < len(r.m.steps) {
+				r.enter()
+				continue
+			}
+			if r.repeat > 0 {
+				r.repeat--
+				next := r.next
+				r.begin(next)
+				continue
+			}
+			ms.stop(i)
+			i--
+			done(r)
+			break
+		}
+	}
+	return changed
+}
+
+// deadline returns the earliest step end of the running macros.
+func (ms *macros) deadline() (time.Time, bool) {
+	var t time.Time
+	for _, r := range ms.running {
+		if t.IsZero() || r.next.Before(t) {
+			t = r.next
+		}
+	}
+	return t, !t.IsZero()
+}
+
+func (ms *macros) status() []protocol.MacroStatus {
+	names := make([]string, 0, len(ms.defs))
+	for name := range ms.defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	status := make([]protocol.MacroStatus, len(names))
+	for i, name := range names {
+		m := ms.defs[name]
+		status[i] = protocol.MacroStatus{
+			Name:     name,
+			Steps:    len(m.steps),
+			Duration: int(m.duration() / time.Millisecond),
+		}
+		if k := ms.find(name); k >= 0 {
+			status[i].Running = true
+			status[i].Step = ms.running[k].step
+			status[i].Repeat = ms.running[k].repeat
+			status[i].Pad = ms.running[k].pad
+		}
+	}
+	return status
+}
+
+// runMacros advances the macros and sends a report if they changed it.
+func (j *JoyStick) runMacros(now time.Time) {
+	if j.macros.advance(now, func(r *macroRun) {
+		j.notify(protocol.NotifyMacroDone, map[string]any{"name": r.name, "pad": r.pad, "cancelled": false})
+	}) {
+		j.sendAll()
+	}
+}
+
+func (j *JoyStick) cancelMacro(name string) bool {
+	i := j.macros.find(name)
+	if i < 0 {
+		return false
+	}
+	r := j.macros.running[i]
+	j.macros.stop(i)
+	j.pads[r.pad].SendState()
+	j.notify(protocol.NotifyMacroDone, map[string]any{"name": name, "pad": r.pad, "cancelled": true})
+	return true
+}
+
+// toMacro parses macro steps of the form
+// {"axes":{"2":1000},"buttons":{"3":true},"hats":{"0":0},"triggers":{"0":255},"ms":80}.
+func toMacro(arg any, layout protocol.Layout) (*macro, error) {
+	list, ok := arg.([]any)
+	if !ok || len(list) == 0 {
+		return nil, invalidParams("invalid argument: steps")
+	}
+	if len(list) > maxMacroSteps {
+		return nil, invalidParams("steps: at most %d steps", maxMacroSteps)
+	}
+	m := &macro{steps: make([]macroStep, len(list))}
+	for i, e := range list {
+		params, ok := e.(map[string]any)
+		if !ok {
+			return nil, invalidParams("invalid argument: steps[%d]", i)
+		}
+		ms, err := intParam(params, "ms")
+		if err != nil {
+			return nil, err
+		}
+		if ms < 0 {
+			return nil, invalidParams("invalid argument: steps[%d].ms", i)
+		}
+		s := &m.steps[i]
+		s.d = time.Duration(ms) * time.Millisecond
+		if s.axes, err = indexMap(params, "axes", layout.Axes, func(v any) (int, error) {
+			return rangedInt(v, "axis value", axisMin, axisMax)
+		}); err != nil {
+			return nil, err
+		}
+		if s.triggers, err = indexMap(params, "triggers", layout.Triggers, func(v any) (int, error) {
+			return rangedInt(v, "trigger value", triggerMin, triggerMax)
+		}); err != nil {
+			return nil, err
+		}
+		if s.hats, err = indexMap(params, "hats", layout.Hats, func(v any) (int, error) {
+			return rangedInt(v, "hat direction", int(HatUp), int(HatCenter))
+		}); err != nil {
+			return nil, err
+		}
+		if s.buttons, err = indexMap(params, "buttons", layout.Buttons, func(v any) (bool, error) {
+			b, ok := v.(bool)
+			if !ok {
+				return false, invalidParams("invalid argument: buttons")
+			}
+			return b, nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// indexMap parses an optional object keyed by input index.
+func indexMap[T any](params map[string]any, name string, count int, value func(any) (T, error)) (map[int]T, error) {
+	arg, ok := params[name]
+	if !ok {
+		return nil, nil
+	}
+	obj, ok := arg.(map[string]any)
+	if !ok {
+		return nil, invalidParams("invalid argument: %s", name)
+	}
+	m := make(map[int]T, len(obj))
+	for key, v := range obj {
+		i, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, invalidParams("invalid argument: %s", name)
+		}
+		if err := checkRange(name+" index", i, 0, count-1); err != nil {
+			return nil, paramError(err)
+		}
+		if m[i], err = value(v); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func rangedInt(v any, name string, min, max int) (int, error) {
+	f, ok := v.(float64)
+	if !ok || f != float64(int(f)) {
+		return 0, invalidParams("invalid argument: %s", name)
+	}
+	if err := checkRange(name, int(f), min, max); err != nil {
+		return 0, paramError(err)
+	}
+	return int(f), nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"io"
+)
+
+// MemDevice is a BlockDevice in RAM that behaves like NOR flash: erasing
+// sets bytes to 0xff and writing can only clear bits. It stands in for
+// machine.Flash off the device.
+type MemDevice struct {
+	data       []byte
+	writeBlock int64
+	eraseBlock int64
+}
+
+func NewMemDevice(size, writeBlock, eraseBlock int64) *MemDevice {
+	d := &MemDevice{data: make([]byte, size), writeBlock: writeBlock, eraseBlock: eraseBlock}
+	for i := range d.data {
+		d.data[i] = 0xff
+	}
+	return d
+}
+
+func (d *MemDevice) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 || off >= int64(len(d.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, d.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (d *MemDevice) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 || off%d.writeBlock != 0 || int64(len(p))%d.writeBlock != 0 {
+		return 0, errors.New("memdevice: unaligned write")
+	}
+	if off+int64(len(p)) > int64(len(d.data)) {
+		return 0, io.ErrShortWrite
+	}
+	for i, b := range p {
+		d.data[off+int64(i)] &= b
+	}
+	return len(p), nil
+}
+
+func (d *MemDevice) Size() int64           { return int64(len(d.data)) }
+func (d *MemDevice) WriteBlockSize() int64 { return d.writeBlock }
+func (d *MemDevice) EraseBlockSize() int64 { return d.eraseBlock }
+
+func (d *MemDevice) EraseBlocks(start, n int64) error {
+	if start < 0 || (start+n)*d.eraseBlock > int64(len(d.data)) {
+		return errors.New("memdevice: erase out of range")
+	}
+	for i := start * d.eraseBlock; i < (start+n)*d.eraseBlock; i++ {
+		d.data[i] = 0xff
+	}
+	return nil
+}
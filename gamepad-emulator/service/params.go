@@ -0,0 +1,122 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nobonobo/gamepad-emulator/hid"
+	"github.com/nobonobo/gamepad-emulator/jsonrpc"
+)
+
+func invalidParams(format string, args ...any) error {
+	return &jsonrpc.Error{
+		Code:    jsonrpc.CodeInvalidParams,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// argErrors are device errors caused by bad arguments.
+var argErrors = []error{ErrButtonMode, ErrInterpolation, ErrReporting, ErrKeyboard, ErrPointer, ErrProfile}
+
+// paramError turns range and argument errors reported by the device into
+// invalid params errors; anything else stays an internal error.
+func paramError(err error) error {
+	var e *ErrRange
+	if errors.As(err, &e) {
+		return invalidParams("%v", err)
+	}
+	for _, target := range argErrors {
+		if errors.Is(err, target) {
+			return invalidParams("%v", err)
+		}
+	}
+	return err
+}
+
+func intParam(params map[string]any, name string) (int, error) {
+	arg, ok := params[name]
+	if !ok {
+		return 0, invalidParams("missing argument: %s", name)
+	}
+	v, ok := arg.(float64)
+	if !ok || v != float64(int(v)) {
+		return 0, invalidParams("invalid argument: %s", name)
+	}
+	return int(v), nil
+}
+
+func floatParam(params map[string]any, name string) (float64, error) {
+	arg, ok := params[name]
+	if !ok {
+		return 0, invalidParams("missing argument: %s", name)
+	}
+	v, ok := arg.(float64)
+	if !ok {
+		return 0, invalidParams("invalid argument: %s", name)
+	}
+	return v, nil
+}
+
+func boolParam(params map[string]any, name string) (bool, error) {
+	arg, ok := params[name]
+	if !ok {
+		return false, invalidParams("missing argument: %s", name)
+	}
+	v, ok := arg.(bool)
+	if !ok {
+		return false, invalidParams("invalid argument: %s", name)
+	}
+	return v, nil
+}
+
+func stringParam(params map[string]any, name string) (string, error) {
+	arg, ok := params[name]
+	if !ok {
+		return "", invalidParams("missing argument: %s", name)
+	}
+	v, ok := arg.(string)
+	if !ok {
+		return "", invalidParams("invalid argument: %s", name)
+	}
+	return v, nil
+}
+
+func intsParam(params map[string]any, name string) ([]int, error) {
+	arg, ok := params[name]
+	if !ok {
+		return nil, invalidParams("missing argument: %s", name)
+	}
+	list, ok := arg.([]any)
+	if !ok {
+		return nil, invalidParams("invalid argument: %s", name)
+	}
+	v := make([]int, len(list))
+	for i, e := range list {
+		f, ok := e.(float64)
+		if !ok || f != float64(int(f)) {
+			return nil, invalidParams("invalid argument: %s", name)
+		}
+		v[i] = int(f)
+	}
+	return v, nil
+}
+
+// keyParam accepts a keyboard usage or a key name such as "enter" or "a".
+func keyParam(params map[string]any, name string) (int, error) {
+	if s, ok := params[name].(string); ok {
+		code, ok := hid.KeyByName(s)
+		if !ok {
+			return 0, invalidParams("unknown key: %s", s)
+		}
+		return code, nil
+	}
+	return intParam(params, name)
+}
+
+// optional returns def when name is absent from params.
+func optional[T any](params map[string]any, name string, def T, get func(map[string]any, string) (T, error)) (T, error) {
+	if _, ok := params[name]; !ok {
+		return def, nil
+	}
+	return get(params, name)
+}
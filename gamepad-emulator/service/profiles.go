@@ -0,0 +1,149 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nobonobo/gamepad-emulator/hid"
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+var ErrProfile = errors.New("invalid profile")
+
+// profile describes the gamepad report of one kind of device. Axes are
+// signed 16 bit, triggers 8 bit, both listed by generic desktop usage.
+type profile struct {
+	name     string
+	usage    int // application collection usage
+	axes     *hid.Group
+	triggers *hid.Group
+	buttons  *hid.Group
+	hats     *hid.Group // up to 4
+	report   *hid.Layout
+}
+
+// valueFlags are the input flags of axes, triggers and buttons in the
+// original gamepad descriptor.
+const valueFlags = hid.Const | hid.Var | hid.Abs
+
+func newProfile(name string, usage int, axes, triggers []int, buttons, hats int) *profile {
+	p := &profile{
+		name:  name,
+		usage: usage,
+		axes: &hid.Group{
+			Page: hid.PageGenericDesktop, Usages: axes,
+			Min: axisMin, Max: axisMax, Size: 16, Flags: valueFlags,
+		},
+		triggers: &hid.Group{
+			Page: hid.PageGenericDesktop, Usages: triggers,
+			Min: triggerMin, Max: triggerMax, Size: 8, Flags: valueFlags,
+		},
+		buttons: hid.Buttons(buttons),
+		hats:    hid.Hats(hats),
+	}
+	p.buttons.Flags = valueFlags
+	p.report = hid.NewLayout(1, p.axes, p.triggers, p.buttons, p.hats)
+	return p
+}
+
+var profiles = []*profile{
+	newProfile(protocol.ProfileGamepad, hid.UsageGamepad,
+		[]int{hid.UsageX, hid.UsageY, hid.UsageRx, hid.UsageRy},
+		[]int{hid.UsageZ, hid.UsageRz},
+		10, 2),
+	// stick, twist rudder and throttle
+	newProfile(protocol.ProfileFlightStick, hid.UsageJoystick,
+		[]int{hid.UsageX, hid.UsageY, hid.UsageRz},
+		[]int{hid.UsageSlider},
+		32, 1),
+	// steering, then accelerator, brake and clutch pedals
+	newProfile(protocol.ProfileWheel, hid.UsageJoystick,
+		[]int{hid.UsageX},
+		[]int{hid.UsageZ, hid.UsageRz, hid.UsageSlider},
+		14, 1),
+}
+
+func findProfile(name string) (*profile, error) {
+	for _, p := range profiles {
+		if p.name == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrProfile, name)
+}
+
+func (p *profile) layout() protocol.Layout {
+	return protocol.Layout{
+		Axes:       p.axes.Count,
+		AxisMin:    axisMin,
+		AxisMax:    axisMax,
+		Triggers:   p.triggers.Count,
+		TriggerMin: triggerMin,
+		TriggerMax: triggerMax,
+		Buttons:    p.buttons.Count,
+		Hats:       p.hats.Count,
+	}
+}
+
+// maxPads limits the virtual gamepads on one device.
+const maxPads = 4
+
+// padReportID returns the report id of pad i. The first pad keeps report 1;
+// the others follow the mouse, pointer and keyboard reports.
+func padReportID(i int) int {
+	if i == 0 {
+		return 1
+	}
+	return reportKeyboard + i
+}
+
+// descriptor returns one gamepad collection per pad followed by the mouse,
+// pointer and keyboard interfaces in composite.go. The vendor output report
+// belongs to the first pad. Each pad saves and restores the global items so
+// that no pad inherits ranges or units from the one before.
+func (p *profile) descriptor(pads int) []byte {
+	var desc []byte
+	for i := range pads {
+		report := *p.report
+		report.ReportID = padReportID(i)
+		desc = hid.Append(desc,
+			hid.Push(),
+			hid.UsagePage(hid.PageGenericDesktop),
+			hid.Usage(p.usage),
+
+			hid.Collection(hid.CollectionApplication),
+
+			hid.Usage(hid.UsagePointer),
+
+			hid.Collection(hid.CollectionPhysical),
+			report.Items(),
+			hid.EndCollection(),
+		)
+		if i == 0 {
+			desc = hid.Append(desc,
+				hid.UsagePage(hid.PageVendor),
+				hid.Usage(0x01),
+				hid.LogicalMinimum(0),
+				hid.LogicalMaximum(255),
+				hid.ReportSize(8),
+				hid.ReportCount(outputReportSize),
+				hid.Output(hid.Data|hid.Var|hid.Abs),
+			)
+		}
+		desc = hid.Append(desc, hid.EndCollection(), hid.Pop())
+	}
+	return hid.Append(desc, compositeDesc)
+}
+
+func (j *JoyStick) profileStatus() []protocol.ProfileStatus {
+	status := make([]protocol.ProfileStatus, len(profiles))
+	for i, p := range profiles {
+		status[i] = protocol.ProfileStatus{
+			Name:   p.name,
+			Layout: p.layout(),
+			Active: p.name == j.js.Profile(),
+			Next:   p.name == j.nextProfile,
+		}
+	}
+	return status
+}
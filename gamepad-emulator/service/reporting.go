@@ -0,0 +1,137 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+// usbPollInterval matches the interrupt endpoint interval; sending more
+// often only queues reports the PC has not read yet.
+const usbPollInterval = time.Millisecond
+
+var ErrReporting = errors.New("invalid reporting")
+
+type reporting struct {
+	onChange  bool
+	interval  time.Duration
+	poll      time.Duration
+	pending   bool // SendState called since the last report
+	dirty     bool // host change not followed by SendState yet
+	updates   int  // host updates since the last report
+	last      time.Time
+	prev      []byte
+	sent      int
+	coalesced int
+}
+
+// auto reports whether reports are sent by Tick rather than by SendState.
+func (r *reporting) auto() bool {
+	return r.onChange || r.interval > 0
+}
+
+func (j *JS) Reporting() protocol.Reporting {
+	r := &j.rep
+	return protocol.Reporting{
+		OnChange: r.onChange,
+		Interval: int(r.interval / time.Millisecond),
+		Poll:     int(r.poll / time.Millisecond),
+	}
+}
+
+// SetReporting sets when reports are sent; zero Poll selects the USB poll
+// interval.
+func (j *JS) SetReporting(v protocol.Reporting) error {
+	if v.Interval < 0 || v.Poll < 0 || v.Poll > 1000 {
+		return fmt.Errorf("%w: interval %d, poll %d", ErrReporting, v.Interval, v.Poll)
+	}
+	r := &j.rep
+	r.onChange = v.OnChange
+	r.interval = time.Duration(v.Interval) * time.Millisecond
+	r.poll = time.Duration(v.Poll) * time.Millisecond
+	if r.poll == 0 {
+		r.poll = usbPollInterval
+	}
+	return nil
+}
+
+func (j *JS) ReportStats() protocol.ReportStats {
+	return protocol.ReportStats{Sent: j.rep.sent, Coalesced: j.rep.coalesced}
+}
+
+// ReportInterval is how often Tick must run: the poll interval while
+// reporting on its own, the interpolation interval while axes may move,
+// the typing interval while text is typed, zero if none of these.
+func (j *JS) ReportInterval() time.Duration {
+	iv := j.interp.interval
+	if j.rep.auto() && (iv == 0 || j.rep.poll < iv) {
+		iv = j.rep.poll
+	}
+	if len(j.comp.typing) > 0 && (iv == 0 || typeInterval < iv) {
+		iv = typeInterval
+	}
+	return iv
+}
+
+// changed records a host change for the coalescing counter. A SendState
+// following changes counts with them as one update. Nothing is counted
+// while the host sends the reports itself.
+func (j *JS) changed() {
+	if !j.rep.auto() {
+		return
+	}
+	j.rep.updates++
+	j.rep.dirty = true
+}
+
+// SendState reports the host state, with button modes and axis
+// interpolation applied, merged with all overlays. While reporting on its
+// own, the report is delayed to the next poll interval if one was just
+// sent.
+func (j *JS) SendState() {
+	r := &j.rep
+	if r.auto() && !r.dirty {
+		r.updates++
+	}
+	r.dirty = false
+	now := time.Now()
+	if r.auto() && (r.pending || now.Sub(r.last) < r.poll) {
+		r.pending = true
+		return
+	}
+	j.build(now)
+	j.send(now)
+}
+
+// flush sends a report when one is due in the automatic reporting mode.
+func (j *JS) flush(now time.Time) {
+	r := &j.rep
+	if !r.auto() || now.Sub(r.last) < r.poll {
+		return
+	}
+	periodic := r.interval > 0 && now.Sub(r.last) >= r.interval
+	if !r.pending && !periodic && !r.onChange {
+		return
+	}
+	j.build(now)
+	if !r.pending && !periodic && bytes.Equal(j.buf, r.prev) {
+		return
+	}
+	j.send(now)
+}
+
+func (j *JS) send(now time.Time) {
+	r := &j.rep
+	j.js.SendReport(j.id, j.buf)
+	copy(r.prev, j.buf)
+	r.last = now
+	r.sent++
+	if r.auto() && r.updates > 1 {
+		r.coalesced += r.updates - 1
+	}
+	r.updates = 0
+	r.pending = false
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+func TestReportStats(t *testing.T) {
+	later := time.Now().Add(2 * time.Second)
+	setState := func(j *JS) {
+		s := j.State()
+		s.Axes[0]++
+		if err := j.SetState(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// update is one SetState with send:true or one FrameSetStateSend.
+	update := func(j *JS) {
+		setState(j)
+		j.SendState()
+	}
+	tests := []struct {
+		name      string
+		reporting protocol.Reporting
+		steps     []func(j *JS)
+		want      protocol.ReportStats
+	}{
+		{
+			name:  "manual",
+			steps: []func(*JS){update, update, func(j *JS) { j.SetAxis(1, 5) }, (*JS).SendState},
+			want:  protocol.ReportStats{Sent: 3},
+		},
+		{
+			name:      "interval, one update per poll",
+			reporting: protocol.Reporting{Interval: 1000, Poll: 1000},
+			steps:     []func(*JS){update, update, func(j *JS) { j.Tick(later) }},
+			want:      protocol.ReportStats{Sent: 2},
+		},
+		{
+			name:      "interval, updates merged",
+			reporting: protocol.Reporting{Interval: 1000, Poll: 1000},
+			steps:     []func(*JS){update, update, update, update, func(j *JS) { j.Tick(later) }},
+			want:      protocol.ReportStats{Sent: 2, Coalesced: 2},
+		},
+		{
+			name:      "on change, setters merged",
+			reporting: protocol.Reporting{OnChange: true, Poll: 1000},
+			steps: []func(*JS){
+				(*JS).SendState,
+				func(j *JS) { j.SetAxis(0, 100) },
+				func(j *JS) { j.SetButton(1, true) },
+				func(j *JS) { j.SetHat(0, HatUp) },
+				func(j *JS) { j.Tick(later) },
+			},
+			want: protocol.ReportStats{Sent: 2, Coalesced: 2},
+		},
+		{
+			name:      "on change, nothing changed",
+			reporting: protocol.Reporting{OnChange: true, Poll: 1000},
+			steps:     []func(*JS){(*JS).SendState, func(j *JS) { j.Tick(later) }},
+			want:      protocol.ReportStats{Sent: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, usb := newTestPad(t)
+			if err := j.SetReporting(tt.reporting); err != nil {
+				t.Fatal(err)
+			}
+			for _, step := range tt.steps {
+				step(j)
+			}
+			if got := j.ReportStats(); got != tt.want {
+				t.Errorf("ReportStats = %+v, want %+v", got, tt.want)
+			}
+			if n := len(usb.sent(1)); n != tt.want.Sent {
+				t.Errorf("%d reports sent, stats say %d", n, tt.want.Sent)
+			}
+		})
+	}
+}
+
+func TestSetReportingRange(t *testing.T) {
+	j, _ := newTestPad(t)
+	for _, v := range []protocol.Reporting{{Interval: -1}, {Poll: -1}, {Poll: 1001}} {
+		if err := j.SetReporting(v); err == nil {
+			t.Errorf("SetReporting(%+v) succeeded", v)
+		}
+	}
+}
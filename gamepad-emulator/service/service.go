@@ -2,157 +2,925 @@ package service
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
-	"strings"
-
-	"machine/usb/hid/joystick"
+	"sync"
+	"time"
 
+	"github.com/nobonobo/gamepad-emulator/hid"
 	"github.com/nobonobo/gamepad-emulator/jsonrpc"
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+// Version is the firmware version, set with -ldflags "-X ...service.Version=...".
+var Version = "dev"
+
+// USB identity defaults, set like Version. An empty SerialNumber uses the
+// unique ID of the board's flash chip.
+var (
+	Product      = "Gamepad Emulator"
+	Manufacturer = "Switch Science"
+	SerialNumber = ""
 )
 
-type method func(params map[string]any) (any, error)
+const tickInterval = 10 * time.Millisecond
 
 type JoyStick struct {
-	methods map[string]method
+	mu          sync.Mutex
+	js          JoySticker // first pad, also serving mouse and keyboard
+	pads        []JoySticker
+	server      *jsonrpc.Server
+	encoding    string
+	frameStats  protocol.FrameStats
+	lastSeq     uint8
+	report      *hid.Descriptor
+	watchdog    watchdog
+	switches    switches
+	leds        []*led
+	macros      macros
+	store       *SettingsStore // nil until UseSettings
+	nextProfile string
+	nextPads    int
+	product     string // identity overrides for the next enumeration
+	serial      string
+	wake        chan struct{}
+	usb         Suspender
+	suspended   bool
+	wmu         sync.Mutex
+	conn        io.Writer
 }
 
-func New() *JoyStick {
-	j := &JoyStick{}
-	j.methods = map[string]method{
-		"Button": func(params map[string]any) (any, error) {
-			arg, ok := params["index"]
-			if !ok {
-				return nil, fmt.Errorf("missing argument: index")
+// padHandler serves a method on the pad selected by the optional "pad"
+// parameter.
+type padHandler func(js JoySticker, params map[string]any) (any, error)
+
+// New serves pads, the virtual gamepads of one device; there must be at
+// least one.
+func New(pads ...JoySticker) *JoyStick {
+	js := pads[0]
+	j := &JoyStick{js: js, pads: pads, encoding: protocol.EncodingJSON}
+	j.watchdog.timeout = defaultWatchdogTimeout
+	j.watchdog.last = time.Now()
+	j.macros.defs = map[string]*macro{}
+	j.nextProfile = js.Profile()
+	j.nextPads = len(pads)
+	j.wake = make(chan struct{}, 1)
+	js.AddOverlay(&j.switches)
+	for i, p := range pads {
+		p.AddOverlay(padMacros{&j.macros, i})
+	}
+	handlers := map[string]padHandler{
+		"Button": func(js JoySticker, params map[string]any) (any, error) {
+			index, err := intParam(params, "index")
+			if err != nil {
+				return nil, err
 			}
-			v, ok := arg.(float64)
-			if !ok {
-				return nil, fmt.Errorf("invalid argument: index")
+			v, err := js.Button(index)
+			if err != nil {
+				return nil, paramError(err)
 			}
-			return js.Button(int(v)), nil
+			return v, nil
 		},
-		"SetButton": func(params map[string]any) (any, error) {
-			arg1, ok := params["index"]
-			if !ok {
-				return nil, fmt.Errorf("missing argument: index")
+		"SetButton": func(js JoySticker, params map[string]any) (any, error) {
+			index, err := intParam(params, "index")
+			if err != nil {
+				return nil, err
 			}
-			v1, ok := arg1.(float64)
-			if !ok {
-				return nil, fmt.Errorf("invalid argument: index")
+			push, err := boolParam(params, "push")
+			if err != nil {
+				return nil, err
 			}
-			arg2, ok := params["push"]
-			if !ok {
-				return nil, fmt.Errorf("missing argument: push")
+			if err := js.SetButton(index, push); err != nil {
+				return nil, paramError(err)
 			}
-			v2, ok := arg2.(bool)
-			if !ok {
-				return nil, fmt.Errorf("invalid argument: push")
+			return true, nil
+		},
+		"ButtonMode": func(js JoySticker, params map[string]any) (any, error) {
+			index, err := intParam(params, "index")
+			if err != nil {
+				return nil, err
+			}
+			v, err := js.ButtonMode(index)
+			if err != nil {
+				return nil, paramError(err)
+			}
+			return v, nil
+		},
+		"ButtonModes": func(js JoySticker, params map[string]any) (any, error) {
+			modes := make(protocol.ButtonModes, js.Layout().Buttons)
+			for i := range modes {
+				modes[i], _ = js.ButtonMode(i)
+			}
+			return modes, nil
+		},
+		"SetButtonMode": func(js JoySticker, params map[string]any) (any, error) {
+			index, err := intParam(params, "index")
+			if err != nil {
+				return nil, err
+			}
+			mode, err := stringParam(params, "mode")
+			if err != nil {
+				return nil, err
+			}
+			rate, err := optional(params, "rate", 0, floatParam)
+			if err != nil {
+				return nil, err
+			}
+			if err := js.SetButtonMode(index, protocol.ButtonMode{Mode: mode, Rate: rate}); err != nil {
+				return nil, paramError(err)
 			}
-			js.SetButton(int(v1), v2)
 			return true, nil
 		},
-		"Hat": func(params map[string]any) (any, error) {
-			arg, ok := params["index"]
-			if !ok {
-				return nil, fmt.Errorf("missing argument: index")
+		"PressFor": func(js JoySticker, params map[string]any) (any, error) {
+			index, err := intParam(params, "index")
+			if err != nil {
+				return nil, err
 			}
-			v, ok := arg.(float64)
-			if !ok {
-				return nil, fmt.Errorf("invalid argument: index")
+			ms, err := intParam(params, "ms")
+			if err != nil {
+				return nil, err
 			}
-			return js.Hat(int(v)), nil
+			if ms <= 0 {
+				return nil, invalidParams("invalid argument: ms")
+			}
+			if err := js.PressFor(index, time.Duration(ms)*time.Millisecond); err != nil {
+				return nil, paramError(err)
+			}
+			js.SendState()
+			return true, nil
 		},
-		"SetHat": func(params map[string]any) (any, error) {
-			arg1, ok := params["index"]
-			if !ok {
-				return nil, fmt.Errorf("missing argument: index")
+		"Hat": func(js JoySticker, params map[string]any) (any, error) {
+			index, err := intParam(params, "index")
+			if err != nil {
+				return nil, err
 			}
-			v1, ok := arg1.(float64)
-			if !ok {
-				return nil, fmt.Errorf("invalid argument: index")
+			v, err := js.Hat(index)
+			if err != nil {
+				return nil, paramError(err)
 			}
-			arg2, ok := params["dir"]
-			if !ok {
-				return nil, fmt.Errorf("missing argument: dir")
+			return v, nil
+		},
+		"SetHat": func(js JoySticker, params map[string]any) (any, error) {
+			index, err := intParam(params, "index")
+			if err != nil {
+				return nil, err
 			}
-			v2, ok := arg2.(float64)
-			if !ok {
-				return nil, fmt.Errorf("invalid argument: dir")
+			dir, err := intParam(params, "dir")
+			if err != nil {
+				return nil, err
+			}
+			if err := js.SetHat(index, HatDirection(dir)); err != nil {
+				return nil, paramError(err)
 			}
-			js.SetHat(int(v1), joystick.HatDirection(uint8(v2)))
 			return true, nil
 		},
-		"Axis": func(params map[string]any) (any, error) {
-			arg, ok := params["index"]
-			if !ok {
-				return nil, fmt.Errorf("missing argument: index")
+		"Axis": func(js JoySticker, params map[string]any) (any, error) {
+			index, err := intParam(params, "index")
+			if err != nil {
+				return nil, err
 			}
-			v, ok := arg.(float64)
-			if !ok {
-				return nil, fmt.Errorf("invalid argument: index")
+			v, err := js.Axis(index)
+			if err != nil {
+				return nil, paramError(err)
 			}
-			return js.Axis(int(v)), nil
+			return v, nil
 		},
-		"SetAxis": func(params map[string]any) (any, error) {
-			arg1, ok := params["index"]
-			if !ok {
-				return nil, fmt.Errorf("missing argument: index")
+		"SetAxis": func(js JoySticker, params map[string]any) (any, error) {
+			index, err := intParam(params, "index")
+			if err != nil {
+				return nil, err
 			}
-			v1, ok := arg1.(float64)
-			if !ok {
-				return nil, fmt.Errorf("invalid argument: index")
+			value, err := intParam(params, "value")
+			if err != nil {
+				return nil, err
 			}
-			arg2, ok := params["value"]
-			if !ok {
-				return nil, fmt.Errorf("missing argument: value")
+			if err := js.SetAxis(index, value); err != nil {
+				return nil, paramError(err)
+			}
+			return true, nil
+		},
+		"AxisFloat": func(js JoySticker, params map[string]any) (any, error) {
+			index, err := intParam(params, "index")
+			if err != nil {
+				return nil, err
+			}
+			v, err := js.Axis(index)
+			if err != nil {
+				return nil, paramError(err)
+			}
+			return js.Layout().AxisFloat(v), nil
+		},
+		"SetAxisFloat": func(js JoySticker, params map[string]any) (any, error) {
+			index, err := intParam(params, "index")
+			if err != nil {
+				return nil, err
+			}
+			value, err := floatParam(params, "value")
+			if err != nil {
+				return nil, err
 			}
-			v2, ok := arg2.(float64)
+			if err := js.SetAxis(index, js.Layout().AxisValue(value)); err != nil {
+				return nil, paramError(err)
+			}
+			return true, nil
+		},
+		"Trigger": func(js JoySticker, params map[string]any) (any, error) {
+			index, err := intParam(params, "index")
+			if err != nil {
+				return nil, err
+			}
+			v, err := js.Trigger(index)
+			if err != nil {
+				return nil, paramError(err)
+			}
+			return v, nil
+		},
+		"SetTrigger": func(js JoySticker, params map[string]any) (any, error) {
+			index, err := intParam(params, "index")
+			if err != nil {
+				return nil, err
+			}
+			value, err := intParam(params, "value")
+			if err != nil {
+				return nil, err
+			}
+			if err := js.SetTrigger(index, value); err != nil {
+				return nil, paramError(err)
+			}
+			return true, nil
+		},
+		"Hello": func(js JoySticker, params map[string]any) (any, error) {
+			version, err := optional(params, "protocol", protocol.Version, intParam)
+			if err != nil {
+				return nil, err
+			}
+			if version != protocol.Version {
+				return nil, invalidParams("unsupported protocol version: %d (firmware speaks %d)", version, protocol.Version)
+			}
+			return j.info(), nil
+		},
+		"GetInfo": func(js JoySticker, params map[string]any) (any, error) {
+			return j.info(), nil
+		},
+		"GetState": func(js JoySticker, params map[string]any) (any, error) {
+			return js.State(), nil
+		},
+		"SetState": func(js JoySticker, params map[string]any) (any, error) {
+			arg, ok := params["state"]
 			if !ok {
-				return nil, fmt.Errorf("invalid argument: value")
+				return nil, invalidParams("missing argument: state")
+			}
+			state, err := toState(arg)
+			if err != nil {
+				return nil, err
+			}
+			send, err := optional(params, "send", false, boolParam)
+			if err != nil {
+				return nil, err
+			}
+			if err := js.SetState(state); err != nil {
+				return nil, invalidParams("%v", err)
+			}
+			if send {
+				js.SendState()
+			}
+			return true, nil
+		},
+		"SetEncoding": func(js JoySticker, params map[string]any) (any, error) {
+			encoding, err := stringParam(params, "encoding")
+			if err != nil {
+				return nil, err
+			}
+			if encoding != protocol.EncodingJSON && encoding != protocol.EncodingBinary {
+				return nil, invalidParams("invalid argument: encoding")
+			}
+			j.encoding = encoding
+			j.frameStats = protocol.FrameStats{}
+			return true, nil
+		},
+		"FrameStats": func(js JoySticker, params map[string]any) (any, error) {
+			stats := j.frameStats
+			stats.Encoding = j.encoding
+			return stats, nil
+		},
+		"LastReport": func(js JoySticker, params map[string]any) (any, error) {
+			if j.report == nil {
+				d, err := hid.Parse(js.Descriptor())
+				if err != nil {
+					return nil, err
+				}
+				j.report = d
+			}
+			return j.report.Decode(js.LastReport())
+		},
+		"SetWatchdog": func(js JoySticker, params map[string]any) (any, error) {
+			timeout, err := intParam(params, "timeout")
+			if err != nil {
+				return nil, err
+			}
+			if timeout < 0 {
+				return nil, invalidParams("invalid argument: timeout")
+			}
+			j.watchdog.timeout = time.Duration(timeout) * time.Millisecond
+			return true, nil
+		},
+		"WatchdogStatus": func(js JoySticker, params map[string]any) (any, error) {
+			return j.watchdogStatus(time.Now()), nil
+		},
+		"Switches": func(js JoySticker, params map[string]any) (any, error) {
+			return protocol.SwitchStatuses(j.switchStatus()), nil
+		},
+		"SetSwitchButton": func(js JoySticker, params map[string]any) (any, error) {
+			index, err := intParam(params, "index")
+			if err != nil {
+				return nil, err
+			}
+			if err := checkRange("switch index", index, 0, len(j.switches)-1); err != nil {
+				return nil, paramError(err)
+			}
+			button, err := intParam(params, "button")
+			if err != nil {
+				return nil, err
+			}
+			if err := checkRange("button index", button, -1, js.Layout().Buttons-1); err != nil {
+				return nil, paramError(err)
+			}
+			j.switches[index].button = button
+			return true, nil
+		},
+		"LEDs": func(js JoySticker, params map[string]any) (any, error) {
+			return protocol.LEDStatuses(j.ledStatus()), nil
+		},
+		"SetLED": func(js JoySticker, params map[string]any) (any, error) {
+			index, err := intParam(params, "index")
+			if err != nil {
+				return nil, err
+			}
+			if err := checkRange("led index", index, 0, len(j.leds)-1); err != nil {
+				return nil, paramError(err)
+			}
+			on, err := boolParam(params, "on")
+			if err != nil {
+				return nil, err
+			}
+			blink, err := optional(params, "blink", 0, intParam)
+			if err != nil {
+				return nil, err
+			}
+			if blink < 0 {
+				return nil, invalidParams("invalid argument: blink")
+			}
+			l := j.leds[index]
+			switch {
+			case !on:
+				l.set(protocol.LEDOff, nil, false, time.Now())
+			case blink > 0:
+				half := time.Duration(blink) * time.Millisecond / 2
+				l.set(protocol.LEDPattern, []time.Duration{half, half}, true, time.Now())
+			default:
+				l.set(protocol.LEDOn, nil, false, time.Now())
+			}
+			return true, nil
+		},
+		"LEDPattern": func(js JoySticker, params map[string]any) (any, error) {
+			index, err := intParam(params, "index")
+			if err != nil {
+				return nil, err
+			}
+			if err := checkRange("led index", index, 0, len(j.leds)-1); err != nil {
+				return nil, paramError(err)
+			}
+			steps, err := optional(params, "pattern", nil, intsParam)
+			if err != nil {
+				return nil, err
+			}
+			if len(steps) > maxLEDPattern {
+				return nil, invalidParams("pattern: at most %d steps", maxLEDPattern)
+			}
+			repeat, err := optional(params, "repeat", true, boolParam)
+			if err != nil {
+				return nil, err
+			}
+			l := j.leds[index]
+			if len(steps) == 0 {
+				l.set(protocol.LEDDefault, nil, false, time.Now())
+				return true, nil
+			}
+			pattern := make([]time.Duration, len(steps))
+			for i, ms := range steps {
+				if ms < 0 {
+					return nil, invalidParams("invalid argument: pattern")
+				}
+				pattern[i] = time.Duration(ms) * time.Millisecond
+			}
+			l.set(protocol.LEDPattern, pattern, repeat, time.Now())
+			return true, nil
+		},
+		"DefineMacro": func(js JoySticker, params map[string]any) (any, error) {
+			name, err := stringParam(params, "name")
+			if err != nil {
+				return nil, err
+			}
+			m, err := toMacro(params["steps"], js.Layout())
+			if err != nil {
+				return nil, err
+			}
+			if m.duration() <= 0 {
+				return nil, invalidParams("steps: macro takes no time")
+			}
+			if _, ok := j.macros.defs[name]; !ok && len(j.macros.defs) >= maxMacros {
+				return nil, invalidParams("at most %d macros", maxMacros)
+			}
+			j.macros.defs[name] = m
+			return true, nil
+		},
+		"DeleteMacro": func(js JoySticker, params map[string]any) (any, error) {
+			name, err := stringParam(params, "name")
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := j.macros.defs[name]; !ok {
+				return nil, invalidParams("unknown macro: %s", name)
+			}
+			j.cancelMacro(name)
+			delete(j.macros.defs, name)
+			return true, nil
+		},
+		"RunMacro": func(js JoySticker, params map[string]any) (any, error) {
+			name, err := stringParam(params, "name")
+			if err != nil {
+				return nil, err
+			}
+			repeat, err := optional(params, "repeat", 0, intParam)
+			if err != nil {
+				return nil, err
+			}
+			if repeat < 0 {
+				return nil, invalidParams("invalid argument: repeat")
+			}
+			pad, _ := optional(params, "pad", 0, intParam)
+			if !j.macros.start(name, pad, repeat, time.Now()) {
+				return nil, invalidParams("unknown macro: %s", name)
+			}
+			j.kick()
+			j.sendAll()
+			return true, nil
+		},
+		"CancelMacro": func(js JoySticker, params map[string]any) (any, error) {
+			name, err := stringParam(params, "name")
+			if err != nil {
+				return nil, err
+			}
+			return j.cancelMacro(name), nil
+		},
+		"Macros": func(js JoySticker, params map[string]any) (any, error) {
+			return protocol.MacroStatuses(j.macros.status()), nil
+		},
+		"Interpolation": func(js JoySticker, params map[string]any) (any, error) {
+			return js.Interpolation(), nil
+		},
+		"SetInterpolation": func(js JoySticker, params map[string]any) (any, error) {
+			mode, err := stringParam(params, "mode")
+			if err != nil {
+				return nil, err
+			}
+			rate, err := optional(params, "rate", 0, floatParam)
+			if err != nil {
+				return nil, err
+			}
+			interval, err := optional(params, "interval", 0, intParam)
+			if err != nil {
+				return nil, err
+			}
+			if err := js.SetInterpolation(protocol.Interpolation{Mode: mode, Rate: rate, Interval: interval}); err != nil {
+				return nil, paramError(err)
+			}
+			j.kick()
+			return true, nil
+		},
+		"Reporting": func(js JoySticker, params map[string]any) (any, error) {
+			return js.Reporting(), nil
+		},
+		"SetReporting": func(js JoySticker, params map[string]any) (any, error) {
+			var v protocol.Reporting
+			var err error
+			if v.OnChange, err = optional(params, "onChange", false, boolParam); err != nil {
+				return nil, err
+			}
+			if v.Interval, err = optional(params, "interval", 0, intParam); err != nil {
+				return nil, err
+			}
+			if v.Poll, err = optional(params, "poll", 0, intParam); err != nil {
+				return nil, err
+			}
+			if err := js.SetReporting(v); err != nil {
+				return nil, paramError(err)
+			}
+			j.kick()
+			return true, nil
+		},
+		"ReportStats": func(js JoySticker, params map[string]any) (any, error) {
+			return js.ReportStats(), nil
+		},
+		"MouseMove": func(js JoySticker, params map[string]any) (any, error) {
+			dx, err := intParam(params, "dx")
+			if err != nil {
+				return nil, err
+			}
+			dy, err := intParam(params, "dy")
+			if err != nil {
+				return nil, err
+			}
+			wheel, err := optional(params, "wheel", 0, intParam)
+			if err != nil {
+				return nil, err
+			}
+			if err := j.js.MouseMove(dx, dy, wheel); err != nil {
+				return nil, paramError(err)
+			}
+			return true, nil
+		},
+		"MouseButton": func(js JoySticker, params map[string]any) (any, error) {
+			index, err := intParam(params, "index")
+			if err != nil {
+				return nil, err
+			}
+			push, err := boolParam(params, "push")
+			if err != nil {
+				return nil, err
+			}
+			if err := j.js.MouseButton(index, push); err != nil {
+				return nil, paramError(err)
+			}
+			return true, nil
+		},
+		"MouseAbs": func(js JoySticker, params map[string]any) (any, error) {
+			x, err := floatParam(params, "x")
+			if err != nil {
+				return nil, err
+			}
+			y, err := floatParam(params, "y")
+			if err != nil {
+				return nil, err
+			}
+			if err := j.js.MouseAbs(x, y); err != nil {
+				return nil, paramError(err)
 			}
-			js.SetAxis(int(v1), int(v2))
 			return true, nil
 		},
-		"SendState": func(params map[string]any) (any, error) {
+		"KeyDown": func(js JoySticker, params map[string]any) (any, error) {
+			key, err := keyParam(params, "key")
+			if err != nil {
+				return nil, err
+			}
+			if err := j.js.KeyDown(key); err != nil {
+				return nil, paramError(err)
+			}
+			return true, nil
+		},
+		"KeyUp": func(js JoySticker, params map[string]any) (any, error) {
+			key, err := keyParam(params, "key")
+			if err != nil {
+				return nil, err
+			}
+			if err := j.js.KeyUp(key); err != nil {
+				return nil, paramError(err)
+			}
+			return true, nil
+		},
+		"TypeString": func(js JoySticker, params map[string]any) (any, error) {
+			text, err := stringParam(params, "text")
+			if err != nil {
+				return nil, err
+			}
+			if err := j.js.TypeString(text); err != nil {
+				return nil, paramError(err)
+			}
+			j.kick()
+			return true, nil
+		},
+		"Profiles": func(js JoySticker, params map[string]any) (any, error) {
+			return protocol.ProfileStatuses(j.profileStatus()), nil
+		},
+		"SetProfile": func(js JoySticker, params map[string]any) (any, error) {
+			name, err := stringParam(params, "name")
+			if err != nil {
+				return nil, err
+			}
+			if _, err := findProfile(name); err != nil {
+				return nil, paramError(err)
+			}
+			if err := j.saveProfile(name); err != nil {
+				return nil, err
+			}
+			j.nextProfile = name
+			return true, nil
+		},
+		"GetSettings": func(js JoySticker, params map[string]any) (any, error) {
+			return j.settings(), nil
+		},
+		"SetSettings": func(js JoySticker, params map[string]any) (any, error) {
+			u, err := j.parseSettings(params)
+			if err != nil {
+				return nil, err
+			}
+			j.applySettings(u)
+			j.sendAll()
+			return true, nil
+		},
+		"SaveSettings": func(js JoySticker, params map[string]any) (any, error) {
+			if err := j.saveSettings(); err != nil {
+				return nil, err
+			}
+			return true, nil
+		},
+		"FactoryReset": func(js JoySticker, params map[string]any) (any, error) {
+			if err := j.factoryReset(); err != nil {
+				return nil, err
+			}
+			return true, nil
+		},
+		"SendState": func(js JoySticker, params map[string]any) (any, error) {
 			js.SendState()
 			return true, nil
 		},
 	}
+	methods := make(map[string]jsonrpc.Handler, len(handlers))
+	for name, h := range handlers {
+		methods[name] = func(params map[string]any) (any, error) {
+			pad, err := optional(params, "pad", 0, intParam)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkRange("pad", pad, 0, len(j.pads)-1); err != nil {
+				return nil, paramError(err)
+			}
+			return h(j.pads[pad], params)
+		}
+	}
+	j.server = jsonrpc.NewServer(methods)
 	return j
 }
 
+func (j *JoyStick) info() protocol.Info {
+	info := protocol.Info{
+		Firmware:  Version,
+		Protocol:  protocol.Version,
+		Profile:   j.js.Profile(),
+		Pads:      len(j.pads),
+		Layout:    j.js.Layout(),
+		Methods:   j.server.Methods(),
+		Encodings: []string{protocol.EncodingJSON, protocol.EncodingBinary},
+	}
+	usbIdentity(&info)
+	return info
+}
+
+func toState(arg any) (protocol.GamepadState, error) {
+	var s protocol.GamepadState
+	m, ok := arg.(map[string]any)
+	if !ok {
+		return s, invalidParams("invalid argument: state")
+	}
+	if v, ok := m["axes"]; ok {
+		list, ok := v.([]any)
+		if !ok {
+			return s, invalidParams("invalid argument: state.axes")
+		}
+		s.Axes = make([]int, len(list))
+		for i, e := range list {
+			f, ok := e.(float64)
+			if !ok {
+				return s, invalidParams("invalid argument: state.axes")
+			}
+			s.Axes[i] = int(f)
+		}
+	}
+	if v, ok := m["triggers"]; ok {
+		list, ok := v.([]any)
+		if !ok {
+			return s, invalidParams("invalid argument: state.triggers")
+		}
+		s.Triggers = make([]int, len(list))
+		for i, e := range list {
+			f, ok := e.(float64)
+			if !ok {
+				return s, invalidParams("invalid argument: state.triggers")
+			}
+			s.Triggers[i] = int(f)
+		}
+	}
+	if v, ok := m["buttons"]; ok {
+		list, ok := v.([]any)
+		if !ok {
+			return s, invalidParams("invalid argument: state.buttons")
+		}
+		s.Buttons = make([]bool, len(list))
+		for i, e := range list {
+			b, ok := e.(bool)
+			if !ok {
+				return s, invalidParams("invalid argument: state.buttons")
+			}
+			s.Buttons[i] = b
+		}
+	}
+	if v, ok := m["hats"]; ok {
+		list, ok := v.([]any)
+		if !ok {
+			return s, invalidParams("invalid argument: state.hats")
+		}
+		s.Hats = make([]int, len(list))
+		for i, e := range list {
+			f, ok := e.(float64)
+			if !ok {
+				return s, invalidParams("invalid argument: state.hats")
+			}
+			s.Hats[i] = int(f)
+		}
+	}
+	return s, nil
+}
+
+// Run serves requests until conn fails. Malformed input is answered with
+// JSON-RPC errors and never ends the session.
 func (j *JoyStick) Run(conn io.ReadWriteCloser) error {
 	defer conn.Close()
-	scanner := bufio.NewScanner(conn)
-	for scanner.Scan() {
-		line := scanner.Text()
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+	j.wmu.Lock()
+	j.conn = conn
+	j.wmu.Unlock()
+	defer func() {
+		j.wmu.Lock()
+		j.conn = nil
+		j.wmu.Unlock()
+	}()
+	done := make(chan struct{})
+	defer close(done)
+	go j.loop(done)
+	resync := false
+	for {
+		scanner := bufio.NewScanner(conn)
+		scanner.Split(splitMessages)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if resync {
+				// rest of an overlong message
+				resync = false
+				continue
+			}
+			if len(line) > 0 && line[0] == protocol.FrameDelimiter {
+				j.mu.Lock()
+				j.touch(time.Now())
+				j.handleFrame(line[1:])
+				j.mu.Unlock()
+				continue
+			}
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 {
+				continue
+			}
+			j.mu.Lock()
+			j.touch(time.Now())
+			resp := j.server.Handle(line)
+			j.mu.Unlock()
+			if err := j.write(resp); err != nil {
+				return err
+			}
+		}
+		if !errors.Is(scanner.Err(), bufio.ErrTooLong) {
+			return scanner.Err()
 		}
-		req := new(jsonrpc.Request)
-		if err := req.UnmarshalJSON([]byte(line)); err != nil {
-			return fmt.Errorf("req unmarshal failed: %w", err)
+		resync = true
+		resp, _ := jsonrpc.NewErrorResponse(jsonrpc.NullID, jsonrpc.CodeParseError, "parse error: message too long").MarshalJSON()
+		if err := j.write(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// loop runs the periodic device tasks while Run serves requests. Macro
+// steps and reports are timed separately so they are not quantized to
+// tickInterval.
+func (j *JoyStick) loop(done <-chan struct{}) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	var report *time.Ticker
+	var reportC <-chan time.Time
+	var interval time.Duration
+	defer func() {
+		if report != nil {
+			report.Stop()
 		}
-		resp := &jsonrpc.Response{
-			ID: req.ID,
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			j.mu.Lock()
+			j.tick(now)
+			j.mu.Unlock()
+		case now := <-reportC:
+			j.mu.Lock()
+			for _, p := range j.pads {
+				p.Tick(now)
+			}
+			j.mu.Unlock()
+		case <-timer.C:
+		case <-j.wake:
 		}
-		r, err := j.methods[req.Method](req.Params)
-		if err != nil {
-			resp.Error = &jsonrpc.Error{
-				Code:    -32603,
-				Message: err.Error(),
+		j.mu.Lock()
+		j.runMacros(time.Now())
+		next, ok := j.macros.deadline()
+		iv := j.reportInterval()
+		j.mu.Unlock()
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
 			}
-		} else {
-			resp.Result = r
 		}
-		respBytes, err := resp.MarshalJSON()
-		if err != nil {
-			return fmt.Errorf("resp marshal failed: %w", err)
+		if ok {
+			timer.Reset(time.Until(next))
 		}
-		if _, err := conn.Write(append(respBytes, '\n')); err != nil {
-			return fmt.Errorf("write failed: %w", err)
+		if iv != interval {
+			interval = iv
+			if report != nil {
+				report.Stop()
+				report, reportC = nil, nil
+			}
+			if iv > 0 {
+				report = time.NewTicker(iv)
+				reportC = report.C
+			}
+		}
+	}
+}
+
+func (j *JoyStick) tick(now time.Time) {
+	j.pollSwitches(now)
+	j.checkWatchdog(now)
+	j.updateLEDs(now)
+	j.pollEvents()
+	for _, p := range j.pads {
+		p.Tick(now)
+	}
+}
+
+// reportInterval is the shortest report interval of the pads, zero if
+// none needs ticking.
+func (j *JoyStick) reportInterval() time.Duration {
+	var iv time.Duration
+	for _, p := range j.pads {
+		if v := p.ReportInterval(); v > 0 && (iv == 0 || v < iv) {
+			iv = v
 		}
 	}
+	return iv
+}
+
+// sendAll sends the state of every pad.
+func (j *JoyStick) sendAll() {
+	for _, p := range j.pads {
+		p.SendState()
+	}
+}
+
+func (j *JoyStick) write(msg []byte) error {
+	if msg == nil {
+		return nil
+	}
+	j.wmu.Lock()
+	defer j.wmu.Unlock()
+	if j.conn == nil {
+		return nil
+	}
+	if _, err := j.conn.Write(append(msg, '\n')); err != nil {
+		return fmt.Errorf("write failed: %w", err)
+	}
 	return nil
 }
+
+// kick makes loop pick up new timing requirements at once.
+func (j *JoyStick) kick() {
+	select {
+	case j.wake <- struct{}{}:
+	default:
+	}
+}
+
+// notify sends a notification to the host, if one is connected.
+func (j *JoyStick) notify(method string, params map[string]any) {
+	msg, err := (&jsonrpc.Request{
+		JsonRpc: jsonrpc.Version,
+		Method:  method,
+		Params:  params,
+	}).MarshalJSON()
+	if err != nil {
+		return
+	}
+	j.write(msg)
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/nobonobo/gamepad-emulator/jsonrpc"
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+// fakeUSB records the reports sent, each prefixed with its report id.
+type fakeUSB struct {
+	mu      sync.Mutex
+	reports [][]byte
+}
+
+func (u *fakeUSB) SendReport(id byte, b []byte) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.reports = append(u.reports, append([]byte{id}, b...))
+}
+
+// last returns the last report sent with id, without the id byte.
+func (u *fakeUSB) last(id byte) []byte {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for i := len(u.reports) - 1; i >= 0; i-- {
+		if u.reports[i][0] == id {
+			return u.reports[i][1:]
+		}
+	}
+	return nil
+}
+
+type pipeConn struct {
+	io.Reader
+	io.Writer
+	closers []io.Closer
+}
+
+func (c pipeConn) Close() error {
+	for _, cl := range c.closers {
+		cl.Close()
+	}
+	return nil
+}
+
+// session serves one JoyStick over in-memory pipes.
+type session struct {
+	t   *testing.T
+	j   *JoyStick
+	usb *fakeUSB
+	in  io.Writer
+	out *bufio.Reader
+}
+
+func startSession(t *testing.T, pads int) *session {
+	t.Helper()
+	usb := &fakeUSB{}
+	list, err := NewPads(usb, protocol.ProfileGamepad, pads)
+	if err != nil {
+		t.Fatal(err)
+	}
+	js := make([]JoySticker, len(list))
+	for i, p := range list {
+		js[i] = p
+	}
+	j := New(js...)
+	j.watchdog.timeout = 0 // keep watchdog trips out of the reports
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- j.Run(pipeConn{Reader: inR, Writer: outW, closers: []io.Closer{inR, outW}})
+	}()
+	t.Cleanup(func() {
+		inW.Close()
+		outR.Close()
+		<-done
+	})
+	return &session{t: t, j: j, usb: usb, in: inW, out: bufio.NewReader(outR)}
+}
+
+func (s *session) send(msg string) {
+	s.t.Helper()
+	if _, err := io.WriteString(s.in, msg+"\n"); err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+func (s *session) recv() []byte {
+	s.t.Helper()
+	line, err := s.out.ReadBytes('\n')
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return bytes.TrimSpace(line)
+}
+
+func (s *session) call(msg string) jsonrpc.Response {
+	s.t.Helper()
+	s.send(msg)
+	var resp jsonrpc.Response
+	if err := resp.UnmarshalJSON(s.recv()); err != nil {
+		s.t.Fatal(err)
+	}
+	return resp
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		code int
+	}{
+		{"unknown method", `{"id":1,"jsonrpc":"2.0","method":"Nope"}`, jsonrpc.CodeMethodNotFound},
+		{"parse error", `{"id":1,"jsonrpc":`, jsonrpc.CodeParseError},
+		{"missing version", `{"id":1,"method":"GetState"}`, jsonrpc.CodeInvalidRequest},
+		{"empty batch", `[]`, jsonrpc.CodeInvalidRequest},
+		{"missing param", `{"id":1,"jsonrpc":"2.0","method":"SetAxis","params":{"index":0}}`, jsonrpc.CodeInvalidParams},
+		{"wrong param type", `{"id":1,"jsonrpc":"2.0","method":"SetAxis","params":{"index":"x","value":1}}`, jsonrpc.CodeInvalidParams},
+		{"index out of range", `{"id":1,"jsonrpc":"2.0","method":"SetButton","params":{"index":10,"push":true}}`, jsonrpc.CodeInvalidParams},
+		{"hat out of range", `{"id":1,"jsonrpc":"2.0","method":"SetHat","params":{"index":2,"dir":0}}`, jsonrpc.CodeInvalidParams},
+		{"pad out of range", `{"id":1,"jsonrpc":"2.0","method":"GetState","params":{"pad":1}}`, jsonrpc.CodeInvalidParams},
+		{"mouse delta", `{"id":1,"jsonrpc":"2.0","method":"MouseMove","params":{"dx":1e15,"dy":0}}`, jsonrpc.CodeInvalidParams},
+		{"turbo rate", `{"id":1,"jsonrpc":"2.0","method":"SetButtonMode","params":{"index":0,"mode":"turbo","rate":1e-300}}`, jsonrpc.CodeInvalidParams},
+		{"no store", `{"id":1,"jsonrpc":"2.0","method":"SaveSettings"}`, jsonrpc.CodeInternalError},
+	}
+	s := startSession(t, 1)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.t = t
+			resp := s.call(tt.msg)
+			if resp.Error == nil {
+				t.Fatalf("no error, result %s", resp.Result)
+			}
+			if resp.Error.Code != tt.code {
+				t.Errorf("code = %d (%s), want %d", resp.Error.Code, resp.Error.Message, tt.code)
+			}
+		})
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	s := startSession(t, 1)
+	s.send(`[{"jsonrpc":"2.0","method":"SetAxis","params":{"index":0,"value":1000}},` +
+		`{"jsonrpc":"2.0","method":"SetButton","params":{"index":0,"push":true}},` +
+		`{"id":2,"jsonrpc":"2.0","method":"SendState"}]`)
+	var resps jsonrpc.BatchResponse
+	if err := resps.UnmarshalJSON(s.recv()); err != nil {
+		t.Fatal(err)
+	}
+	if len(resps) != 1 || resps[0].ID != jsonrpc.IntID(2) || string(resps[0].Result) != "true" {
+		t.Fatalf("batch reply = %+v, want only id 2 with true", resps)
+	}
+	// 4 axes, 2 triggers, 10 buttons padded to 16 bits, 2 centered hats
+	want := []byte{0xe8, 0x03, 0, 0, 0, 0, 0, 0, 0, 0, 0x01, 0x00, 0x88}
+	if got := s.usb.last(1); !bytes.Equal(got, want) {
+		t.Errorf("report % x, want % x", got, want)
+	}
+}
+
+func TestRunNotification(t *testing.T) {
+	s := startSession(t, 1)
+	// notifications get no reply, so the next line answers the request
+	s.send(`{"jsonrpc":"2.0","method":"SetHat","params":{"index":0,"dir":2}}`)
+	s.send(`{"jsonrpc":"2.0","method":"Nope"}`)
+	resp := s.call(`{"id":7,"jsonrpc":"2.0","method":"GetState"}`)
+	if resp.ID != jsonrpc.IntID(7) || resp.Error != nil {
+		t.Fatalf("reply = %+v", resp)
+	}
+	var state protocol.GamepadState
+	if err := json.Unmarshal(resp.Result, &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Hats[0] != 2 || state.Hats[1] != 8 {
+		t.Errorf("hats = %v, want [2 8]", state.Hats)
+	}
+	if len(s.usb.reports) != 0 {
+		t.Errorf("%d reports sent without SendState", len(s.usb.reports))
+	}
+}
+
+func TestRunBinaryFrame(t *testing.T) {
+	s := startSession(t, 1)
+	s.call(`{"id":1,"jsonrpc":"2.0","method":"SetEncoding","params":{"encoding":"binary"}}`)
+	state := protocol.GamepadState{
+		Axes:     []int{0, 300, 0, 0},
+		Triggers: []int{0, 0},
+		Buttons:  make([]bool, 10),
+		Hats:     []int{8, 8},
+	}
+	payload, err := state.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := protocol.AppendFrame(nil, protocol.FrameSetStateSend, 1, payload)
+	if _, err := s.in.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+	resp := s.call(`{"id":2,"jsonrpc":"2.0","method":"FrameStats"}`)
+	var stats protocol.FrameStats
+	if err := json.Unmarshal(resp.Result, &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Frames != 1 || stats.Errors != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	want := []byte{0, 0, 0x2c, 0x01, 0, 0, 0, 0, 0, 0, 0, 0, 0x88}
+	if got := s.usb.last(1); !bytes.Equal(got, want) {
+		t.Errorf("report % x, want % x", got, want)
+	}
+}
+
+func TestRunPads(t *testing.T) {
+	s := startSession(t, 2)
+	if resp := s.call(`{"id":1,"jsonrpc":"2.0","method":"SetAxis","params":{"pad":1,"index":0,"value":-2}}`); resp.Error != nil {
+		t.Fatal(resp.Error)
+	}
+	s.call(`{"id":2,"jsonrpc":"2.0","method":"SendState","params":{"pad":1}}`)
+	want := []byte{0xfe, 0xff, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x88}
+	if got := s.usb.last(byte(padReportID(1))); !bytes.Equal(got, want) {
+		t.Errorf("pad 1 report % x, want % x", got, want)
+	}
+	if got := s.usb.last(1); got != nil {
+		t.Errorf("pad 0 sent % x", got)
+	}
+
+	s.call(`{"id":3,"jsonrpc":"2.0","method":"SetEncoding","params":{"encoding":"binary"}}`)
+	state := protocol.GamepadState{
+		Axes:     []int{0, 300, 0, 0},
+		Triggers: []int{0, 0},
+		Buttons:  make([]bool, 10),
+		Hats:     []int{8, 8},
+	}
+	payload, err := state.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := protocol.AppendFrame(nil, protocol.FrameSetPadStateSend, 1, append([]byte{1}, payload...))
+	if _, err := s.in.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+	s.call(`{"id":4,"jsonrpc":"2.0","method":"FrameStats"}`)
+	want = []byte{0, 0, 0x2c, 0x01, 0, 0, 0, 0, 0, 0, 0, 0, 0x88}
+	if got := s.usb.last(byte(padReportID(1))); !bytes.Equal(got, want) {
+		t.Errorf("pad 1 frame report % x, want % x", got, want)
+	}
+}
@@ -0,0 +1,359 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mailru/easyjson/jlexer"
+	"github.com/mailru/easyjson/jwriter"
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+// settingsUpdate holds parsed settings; nil and empty fields are left
+// unchanged.
+type settingsUpdate struct {
+	watchdog    *time.Duration
+	profile     string
+	pads        int
+	product     *string
+	serial      *string
+	buttonModes []protocol.ButtonMode
+	macros      map[string]*macro
+}
+
+// maxUSBString limits product and serial overrides.
+const maxUSBString = 32
+
+// BootSettings returns the settings saved in s that apply at enumeration:
+// the profile, defaulting to the gamepad, the number of pads, defaulting to
+// one, and the identity overrides. Invalid values are replaced by the
+// defaults.
+func BootSettings(s *SettingsStore) protocol.Settings {
+	v := protocol.Settings{Profile: protocol.ProfileGamepad, Pads: 1}
+	b, err := s.Load()
+	if err != nil {
+		return v
+	}
+	if err := v.UnmarshalJSON(b); err != nil {
+		return protocol.Settings{Profile: protocol.ProfileGamepad, Pads: 1}
+	}
+	if _, err := findProfile(v.Profile); err != nil {
+		v.Profile = protocol.ProfileGamepad
+	}
+	if checkRange("pads", v.Pads, 1, maxPads) != nil {
+		v.Pads = 1
+	}
+	if checkUSBString("product", v.Product) != nil {
+		v.Product = ""
+	}
+	if checkUSBString("serial", v.Serial) != nil {
+		v.Serial = ""
+	}
+	return v
+}
+
+// checkUSBString accepts printable ASCII strings for USB string
+// descriptors; empty selects the default.
+func checkUSBString(name, s string) error {
+	if len(s) > maxUSBString {
+		return invalidParams("%s: at most %d characters", name, maxUSBString)
+	}
+	for _, c := range []byte(s) {
+		if c < 0x20 || c > 0x7e {
+			return invalidParams("%s: printable ASCII only", name)
+		}
+	}
+	return nil
+}
+
+// usbStringParam returns an optional identity override, nil if absent.
+func usbStringParam(params map[string]any, name string) (*string, error) {
+	if _, ok := params[name]; !ok {
+		return nil, nil
+	}
+	v, err := stringParam(params, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUSBString(name, v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// UseSettings applies the settings saved in s and makes SaveSettings write
+// there. Settings that do not apply to the current layout are skipped and
+// reported in the error.
+func (j *JoyStick) UseSettings(s *SettingsStore) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.store = s
+	b, err := s.Load()
+	if errors.Is(err, ErrNoSettings) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	in := jlexer.Lexer{Data: b}
+	params, ok := in.Interface().(map[string]any)
+	if err := in.Error(); err != nil || !ok {
+		return fmt.Errorf("settings: malformed: %v", err)
+	}
+	var errs []error
+	for key, v := range params {
+		u, err := j.parseSettings(map[string]any{key: v})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("settings: %s: %w", key, err))
+			continue
+		}
+		j.applySettings(u)
+	}
+	return errors.Join(errs...)
+}
+
+func (j *JoyStick) parseSettings(params map[string]any) (*settingsUpdate, error) {
+	u := &settingsUpdate{}
+	layout := j.js.Layout()
+	if _, ok := params["watchdog"]; ok {
+		ms, err := intParam(params, "watchdog")
+		if err != nil {
+			return nil, err
+		}
+		if ms < 0 {
+			return nil, invalidParams("invalid argument: watchdog")
+		}
+		d := time.Duration(ms) * time.Millisecond
+		u.watchdog = &d
+	}
+	if _, ok := params["profile"]; ok {
+		name, err := stringParam(params, "profile")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := findProfile(name); err != nil {
+			return nil, paramError(err)
+		}
+		u.profile = name
+	}
+	if _, ok := params["pads"]; ok {
+		n, err := intParam(params, "pads")
+		if err != nil {
+			return nil, err
+		}
+		if err := checkRange("pads", n, 1, maxPads); err != nil {
+			return nil, paramError(err)
+		}
+		u.pads = n
+	}
+	var err error
+	if u.product, err = usbStringParam(params, "product"); err != nil {
+		return nil, err
+	}
+	if u.serial, err = usbStringParam(params, "serial"); err != nil {
+		return nil, err
+	}
+	if arg, ok := params["buttonModes"]; ok {
+		list, ok := arg.([]any)
+		if !ok {
+			return nil, invalidParams("invalid argument: buttonModes")
+		}
+		if len(list) > layout.Buttons {
+			return nil, invalidParams("buttonModes: want at most %d, got %d", layout.Buttons, len(list))
+		}
+		u.buttonModes = make([]protocol.ButtonMode, len(list))
+		for i, e := range list {
+			m, ok := e.(map[string]any)
+			if !ok {
+				return nil, invalidParams("invalid argument: buttonModes[%d]", i)
+			}
+			mode, err := stringParam(m, "mode")
+			if err != nil {
+				return nil, err
+			}
+			rate, err := optional(m, "rate", 0, floatParam)
+			if err != nil {
+				return nil, err
+			}
+			if u.buttonModes[i], err = checkButtonMode(protocol.ButtonMode{Mode: mode, Rate: rate}); err != nil {
+				return nil, paramError(err)
+			}
+		}
+	}
+	if arg, ok := params["macros"]; ok {
+		list, ok := arg.([]any)
+		if !ok {
+			return nil, invalidParams("invalid argument: macros")
+		}
+		if len(list) > maxMacros {
+			return nil, invalidParams("at most %d macros", maxMacros)
+		}
+		u.macros = make(map[string]*macro, len(list))
+		for i, e := range list {
+			m, ok := e.(map[string]any)
+			if !ok {
+				return nil, invalidParams("invalid argument: macros[%d]", i)
+			}
+			name, err := stringParam(m, "name")
+			if err != nil {
+				return nil, err
+			}
+			if u.macros[name], err = toMacro(m["steps"], layout); err != nil {
+				return nil, err
+			}
+			if u.macros[name].duration() <= 0 {
+				return nil, invalidParams("macro %s takes no time", name)
+			}
+		}
+	}
+	return u, nil
+}
+
+func (j *JoyStick) applySettings(u *settingsUpdate) {
+	if u.watchdog != nil {
+		j.watchdog.timeout = *u.watchdog
+	}
+	if u.profile != "" {
+		j.nextProfile = u.profile
+	}
+	if u.pads != 0 {
+		j.nextPads = u.pads
+	}
+	if u.product != nil {
+		j.product = *u.product
+	}
+	if u.serial != nil {
+		j.serial = *u.serial
+	}
+	for _, p := range j.pads {
+		for i, m := range u.buttonModes {
+			p.SetButtonMode(i, m)
+		}
+	}
+	if u.macros != nil {
+		for len(j.macros.running) > 0 {
+			j.cancelMacro(j.macros.running[0].name)
+		}
+		j.macros.defs = u.macros
+	}
+}
+
+// settings collects the current settings.
+func (j *JoyStick) settings() protocol.Settings {
+	s := protocol.Settings{
+		Watchdog:    int(j.watchdog.timeout / time.Millisecond),
+		Profile:     j.nextProfile,
+		Pads:        j.nextPads,
+		Product:     j.product,
+		Serial:      j.serial,
+		ButtonModes: make([]protocol.ButtonMode, j.js.Layout().Buttons),
+		Macros:      make([]protocol.MacroDef, 0, len(j.macros.defs)),
+	}
+	for i := range s.ButtonModes {
+		s.ButtonModes[i], _ = j.js.ButtonMode(i)
+	}
+	names := make([]string, 0, len(j.macros.defs))
+	for name := range j.macros.defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		m := j.macros.defs[name]
+		def := protocol.MacroDef{Name: name, Steps: make([]protocol.MacroStep, len(m.steps))}
+		for i, step := range m.steps {
+			def.Steps[i] = protocol.MacroStep{
+				Axes:     byIndex(step.axes),
+				Triggers: byIndex(step.triggers),
+				Buttons:  byIndex(step.buttons),
+				Hats:     byIndex(step.hats),
+				Ms:       int(step.d / time.Millisecond),
+			}
+		}
+		s.Macros = append(s.Macros, def)
+	}
+	return s
+}
+
+func byIndex[T any](m map[int]T) map[string]T {
+	if len(m) == 0 {
+		return nil
+	}
+	s := make(map[string]T, len(m))
+	for i, v := range m {
+		s[strconv.Itoa(i)] = v
+	}
+	return s
+}
+
+func (j *JoyStick) saveSettings() error {
+	if j.store == nil {
+		return ErrNoStore
+	}
+	s := j.settings()
+	b, err := s.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return j.store.Save(b)
+}
+
+// saveProfile stores name as the boot profile and keeps the rest of the
+// saved settings; unsaved changes are not persisted with it.
+func (j *JoyStick) saveProfile(name string) error {
+	if j.store == nil {
+		return ErrNoStore
+	}
+	b, err := j.store.Load()
+	switch {
+	case errors.Is(err, ErrNoSettings):
+		w := jwriter.Writer{}
+		w.RawString(`{"profile":`)
+		w.String(name)
+		w.RawByte('}')
+		if b, err = w.BuildBytes(); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		var s protocol.Settings
+		if err := s.UnmarshalJSON(b); err != nil {
+			return fmt.Errorf("settings: malformed: %v", err)
+		}
+		s.Profile = name
+		if b, err = s.MarshalJSON(); err != nil {
+			return err
+		}
+	}
+	return j.store.Save(b)
+}
+
+// factoryReset erases the saved settings and restores the defaults. The
+// device enumerates as a single gamepad with the default identity after the
+// next reset.
+func (j *JoyStick) factoryReset() error {
+	if j.store == nil {
+		return ErrNoStore
+	}
+	if err := j.store.Erase(); err != nil {
+		return err
+	}
+	j.watchdog.timeout = defaultWatchdogTimeout
+	j.nextProfile = protocol.ProfileGamepad
+	j.nextPads = 1
+	j.product, j.serial = "", ""
+	for _, p := range j.pads {
+		for i := range p.Layout().Buttons {
+			p.SetButtonMode(i, protocol.ButtonMode{Mode: protocol.ButtonNormal})
+		}
+	}
+	for len(j.macros.running) > 0 {
+		j.cancelMacro(j.macros.running[0].name)
+	}
+	j.macros.defs = map[string]*macro{}
+	j.sendAll()
+	return nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// BlockDevice is flash memory that is erased in blocks before it is
+// written. machine.Flash implements it.
+type BlockDevice interface {
+	ReadAt(p []byte, off int64) (int, error)
+	WriteAt(p []byte, off int64) (int, error)
+	Size() int64
+	WriteBlockSize() int64
+	EraseBlockSize() int64
+	EraseBlocks(start, len int64) error
+}
+
+const (
+	settingsVersion = 1
+	settingsSlot    = 16 * 1024 // per copy, rounded up to erase blocks
+	headerSize      = 20
+)
+
+var settingsMagic = [4]byte{'G', 'P', 'S', 'T'}
+
+var (
+	ErrNoSettings = errors.New("settings: none stored")
+	ErrNoStore    = errors.New("settings: no store")
+)
+
+// SettingsStore keeps settings in two slots at the start of a block
+// device. Each save goes to the slot not holding the newest copy, so a
+// power loss while saving leaves the previous settings readable.
+//
+// A slot starts with a header: magic, version (uint16), reserved
+// (uint16), sequence number, payload length and CRC-32 of the payload,
+// all little endian.
+type SettingsStore struct {
+	dev  BlockDevice
+	slot int64
+}
+
+func NewSettingsStore(dev BlockDevice) *SettingsStore {
+	erase := dev.EraseBlockSize()
+	return &SettingsStore{dev: dev, slot: (settingsSlot + erase - 1) / erase * erase}
+}
+
+type slotHeader struct {
+	version int
+	seq     uint32
+	length  int
+	crc     uint32
+}
+
+// read returns the payload of slot i.
+func (s *SettingsStore) read(i int) (slotHeader, []byte, error) {
+	var h slotHeader
+	var b [headerSize]byte
+	if _, err := s.dev.ReadAt(b[:], int64(i)*s.slot); err != nil {
+		return h, nil, err
+	}
+	if [4]byte(b[:4]) != settingsMagic {
+		return h, nil, ErrNoSettings
+	}
+	h.version = int(binary.LittleEndian.Uint16(b[4:]))
+	h.seq = binary.LittleEndian.Uint32(b[8:])
+	h.length = int(binary.LittleEndian.Uint32(b[12:]))
+	h.crc = binary.LittleEndian.Uint32(b[16:])
+	if h.version != settingsVersion {
+		return h, nil, fmt.Errorf("settings: unsupported version %d", h.version)
+	}
+	if h.length < 0 || h.length > int(s.slot)-headerSize {
+		return h, nil, fmt.Errorf("settings: bad length %d", h.length)
+	}
+	payload := make([]byte, h.length)
+	if _, err := s.dev.ReadAt(payload, int64(i)*s.slot+headerSize); err != nil {
+		return h, nil, err
+	}
+	if crc32.ChecksumIEEE(payload) != h.crc {
+		return h, nil, errors.New("settings: crc mismatch")
+	}
+	return h, payload, nil
+}
+
+// newest returns the slot holding the newest valid copy, or -1.
+func (s *SettingsStore) newest() (int, slotHeader, []byte, error) {
+	best, hb, pb := -1, slotHeader{}, []byte(nil)
+	var lastErr error = ErrNoSettings
+	for i := range 2 {
+		h, p, err := s.read(i)
+		if err != nil {
+			if !errors.Is(err, ErrNoSettings) {
+				lastErr = err
+			}
+			continue
+		}
+		if best < 0 || int32(h.seq-hb.seq) > 0 {
+			best, hb, pb = i, h, p
+		}
+	}
+	if best < 0 {
+		return -1, hb, nil, lastErr
+	}
+	return best, hb, pb, nil
+}
+
+// Load returns the newest stored settings payload.
+func (s *SettingsStore) Load() ([]byte, error) {
+	_, _, p, err := s.newest()
+	return p, err
+}
+
+// Save writes payload to the older slot.
+func (s *SettingsStore) Save(payload []byte) error {
+	if s.dev.Size() < 2*s.slot {
+		return fmt.Errorf("settings: flash too small: %d bytes", s.dev.Size())
+	}
+	if len(payload) > int(s.slot)-headerSize {
+		return fmt.Errorf("settings: %d bytes do not fit", len(payload))
+	}
+	slot, h, _, _ := s.newest()
+	seq := h.seq + 1
+	slot = (slot + 1) % 2 // slot 0 when none is valid
+	wb := s.dev.WriteBlockSize()
+	n := (int64(headerSize+len(payload)) + wb - 1) / wb * wb
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = 0xff
+	}
+	copy(b, settingsMagic[:])
+	binary.LittleEndian.PutUint16(b[4:], settingsVersion)
+	binary.LittleEndian.PutUint16(b[6:], 0)
+	binary.LittleEndian.PutUint32(b[8:], seq)
+	binary.LittleEndian.PutUint32(b[12:], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(b[16:], crc32.ChecksumIEEE(payload))
+	copy(b[headerSize:], payload)
+	erase := s.dev.EraseBlockSize()
+	if err := s.dev.EraseBlocks(int64(slot)*s.slot/erase, s.slot/erase); err != nil {
+		return err
+	}
+	if _, err := s.dev.WriteAt(b, int64(slot)*s.slot); err != nil {
+		return err
+	}
+	if _, _, err := s.read(slot); err != nil {
+		return fmt.Errorf("settings: verify: %w", err)
+	}
+	return nil
+}
+
+// Erase removes both copies.
+func (s *SettingsStore) Erase() error {
+	erase := s.dev.EraseBlockSize()
+	return s.dev.EraseBlocks(0, 2*s.slot/erase)
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+const (
+	testWriteBlock = 256
+	testEraseBlock = 4096
+)
+
+func newTestStore() (*SettingsStore, *MemDevice) {
+	dev := NewMemDevice(64*1024, testWriteBlock, testEraseBlock)
+	return NewSettingsStore(dev), dev
+}
+
+// tornDevice loses power partway through the next write: only the first
+// n bytes reach the flash.
+type tornDevice struct {
+	*MemDevice
+	n int
+}
+
+func (d *tornDevice) WriteAt(p []byte, off int64) (int, error) {
+	for i, b := range p[:d.n] {
+		d.data[off+int64(i)] &= b
+	}
+	return d.n, errors.New("power lost")
+}
+
+func TestStoreSlots(t *testing.T) {
+	s, _ := newTestStore()
+	if _, err := s.Load(); !errors.Is(err, ErrNoSettings) {
+		t.Fatalf("empty Load = %v, want ErrNoSettings", err)
+	}
+	for i, v := range []string{"a", "b", "c"} {
+		if err := s.Save([]byte(v)); err != nil {
+			t.Fatal(err)
+		}
+		b, err := s.Load()
+		if err != nil || string(b) != v {
+			t.Fatalf("Load = %q, %v, want %q", b, err, v)
+		}
+		// saves alternate between the slots, the other keeps the last copy
+		h, p, err := s.read(i % 2)
+		if err != nil || string(p) != v || h.seq != uint32(i+1) {
+			t.Errorf("save %d: slot %d = %q seq %d, %v", i, i%2, p, h.seq, err)
+		}
+		if i > 0 {
+			if _, p, _ := s.read((i + 1) % 2); string(p) != []string{"a", "b"}[i-1] {
+				t.Errorf("save %d: older slot = %q", i, p)
+			}
+		}
+	}
+	if err := s.Erase(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Load(); !errors.Is(err, ErrNoSettings) {
+		t.Errorf("Load after Erase = %v, want ErrNoSettings", err)
+	}
+}
+
+func TestStoreCRC(t *testing.T) {
+	s, dev := newTestStore()
+	for _, v := range []string{"old", "new"} {
+		if err := s.Save([]byte(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dev.data[s.slot+headerSize] ^= 0x01 // newest copy is in slot 1
+	b, err := s.Load()
+	if err != nil || string(b) != "old" {
+		t.Fatalf("Load = %q, %v, want the older copy", b, err)
+	}
+	dev.data[headerSize] ^= 0x01
+	if _, err := s.Load(); err == nil || errors.Is(err, ErrNoSettings) {
+		t.Errorf("Load of two corrupt copies = %v, want a crc error", err)
+	}
+}
+
+func TestStoreVersion(t *testing.T) {
+	s, dev := newTestStore()
+	if err := s.Save([]byte(`{"profile":"wheel","pads":2}`)); err != nil {
+		t.Fatal(err)
+	}
+	binary.LittleEndian.PutUint16(dev.data[4:], settingsVersion+1)
+	if _, err := s.Load(); err == nil || errors.Is(err, ErrNoSettings) {
+		t.Fatalf("Load = %v, want a version error", err)
+	}
+	want := protocol.Settings{Profile: protocol.ProfileGamepad, Pads: 1}
+	if got := BootSettings(s); got.Profile != want.Profile || got.Pads != want.Pads {
+		t.Errorf("BootSettings = %+v, want the defaults", got)
+	}
+	pads, err := NewPads(&fakeUSB{}, protocol.ProfileGamepad, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := New(pads[0]).UseSettings(s); err == nil {
+		t.Error("UseSettings accepted an unsupported version")
+	}
+}
+
+func TestStoreTornWrite(t *testing.T) {
+	mem := NewMemDevice(64*1024, testWriteBlock, testEraseBlock)
+	s := NewSettingsStore(mem)
+	if err := s.Save([]byte("first")); err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int{0, 8, headerSize, headerSize + 3} {
+		torn := NewSettingsStore(&tornDevice{MemDevice: mem, n: n})
+		if err := torn.Save([]byte("second")); err == nil {
+			t.Fatalf("torn after %d bytes: Save succeeded", n)
+		}
+		b, err := s.Load()
+		if err != nil || string(b) != "first" {
+			t.Errorf("torn after %d bytes: Load = %q, %v, want the previous copy", n, b, err)
+		}
+	}
+	if err := s.Save([]byte("third")); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := s.Load(); string(b) != "third" {
+		t.Errorf("Load after recovery = %q", b)
+	}
+}
+
+func TestSaveSettingsBoot(t *testing.T) {
+	pads, err := NewPads(&fakeUSB{}, protocol.ProfileGamepad, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := New(pads[0])
+	if err := j.saveSettings(); !errors.Is(err, ErrNoStore) {
+		t.Fatalf("saveSettings without a store = %v, want ErrNoStore", err)
+	}
+	s, _ := newTestStore()
+	if err := j.UseSettings(s); err != nil {
+		t.Fatal(err)
+	}
+	j.nextProfile, j.nextPads, j.product = protocol.ProfileWheel, 3, "Racer"
+	if err := j.saveSettings(); err != nil {
+		t.Fatal(err)
+	}
+	got := BootSettings(s)
+	if got.Profile != protocol.ProfileWheel || got.Pads != 3 || got.Product != "Racer" {
+		t.Errorf("BootSettings = %+v", got)
+	}
+}
+
+func TestSaveProfile(t *testing.T) {
+	pads, err := NewPads(&fakeUSB{}, protocol.ProfileGamepad, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := New(pads[0])
+	s, _ := newTestStore()
+	if err := j.UseSettings(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.saveProfile(protocol.ProfileWheel); err != nil {
+		t.Fatal(err)
+	}
+	if got := BootSettings(s); got.Profile != protocol.ProfileWheel || got.Pads != 1 {
+		t.Fatalf("BootSettings without saved settings = %+v", got)
+	}
+	j.product = "Saved"
+	if err := j.saveSettings(); err != nil {
+		t.Fatal(err)
+	}
+	// unsaved changes stay out of the store
+	j.product = "Unsaved"
+	toggle := protocol.ButtonMode{Mode: protocol.ButtonToggle}
+	if err := pads[0].SetButtonMode(0, toggle); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.saveProfile(protocol.ProfileFlightStick); err != nil {
+		t.Fatal(err)
+	}
+	got := BootSettings(s)
+	if got.Profile != protocol.ProfileFlightStick || got.Product != "Saved" {
+		t.Errorf("BootSettings = %+v", got)
+	}
+	pads, err = NewPads(&fakeUSB{}, protocol.ProfileGamepad, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := New(pads[0]).UseSettings(s); err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := pads[0].ButtonMode(0); m.Mode == protocol.ButtonToggle {
+		t.Error("saveProfile persisted an unsaved button mode")
+	}
+}
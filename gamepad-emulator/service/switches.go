@@ -0,0 +1,74 @@
+package service
+
+import (
+	"time"
+
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+const debounceTime = 20 * time.Millisecond
+
+// Input is a digital input such as machine.Pin.
+type Input interface {
+	Get() bool
+}
+
+type button struct {
+	in        Input
+	activeLow bool
+	candidate bool
+	since     time.Time
+	pressed   bool
+	button    int // gamepad button index, -1 if unmapped
+}
+
+// switches merges mapped on-board switches into the report.
+type switches []*button
+
+func (s switches) Apply(st *protocol.GamepadState) {
+	for _, b := range s {
+		if b.pressed && b.button >= 0 && b.button < len(st.Buttons) {
+			st.Buttons[b.button] = true
+		}
+	}
+}
+
+// AddSwitch registers an on-board switch. Changes are debounced, reported
+// to the host as Switch notifications and optionally merged into a button.
+func (j *JoyStick) AddSwitch(in Input, activeLow bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.switches = append(j.switches, &button{in: in, activeLow: activeLow, button: -1})
+}
+
+func (j *JoyStick) pollSwitches(now time.Time) {
+	send := false
+	for i, b := range j.switches {
+		raw := b.in.Get() != b.activeLow
+		if raw != b.candidate {
+			b.candidate = raw
+			b.since = now
+			continue
+		}
+		if b.candidate == b.pressed || now.Sub(b.since) < debounceTime {
+			continue
+		}
+		b.pressed = b.candidate
+		j.notify(protocol.NotifySwitch, map[string]any{
+			"index":   i,
+			"pressed": b.pressed,
+		})
+		send = send || b.button >= 0
+	}
+	if send {
+		j.js.SendState()
+	}
+}
+
+func (j *JoyStick) switchStatus() []protocol.SwitchStatus {
+	status := make([]protocol.SwitchStatus, len(j.switches))
+	for i, b := range j.switches {
+		status[i] = protocol.SwitchStatus{Pressed: b.pressed, Button: b.button}
+	}
+	return status
+}
@@ -0,0 +1,58 @@
+//go:build tinygo
+
+package service
+
+import (
+	"cmp"
+	"encoding/hex"
+	"machine"
+	"machine/usb"
+	"strings"
+
+	"machine/usb/hid/joystick"
+
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+func usbIdentity(info *protocol.Info) {
+	info.VendorID = int(usb.VendorID)
+	info.ProductID = int(usb.ProductID)
+	info.Manufacturer = usb.Manufacturer
+	info.Product = usb.Product
+	info.Serial = usb.Serial
+}
+
+// NewUSB sets the USB identity and registers the HID interface with the
+// pads laid out by the boot settings, and returns their states.
+func NewUSB(boot protocol.Settings) ([]JoySticker, error) {
+	usb.VendorID = 0x2786
+	usb.ProductID = 0x000a
+	usb.Manufacturer = Manufacturer
+	usb.Product = cmp.Or(boot.Product, Product)
+	usb.Serial = cmp.Or(boot.Serial, SerialNumber, strings.ToUpper(hex.EncodeToString(machine.DeviceID())))
+
+	pads, err := NewPads(nil, boot.Profile, max(boot.Pads, 1))
+	if err != nil {
+		return nil, err
+	}
+	p := pads[0].profile
+	axes := make([]joystick.Constraint, 0, p.axes.Count+p.triggers.Count)
+	for range p.axes.Count {
+		axes = append(axes, joystick.Constraint{MinIn: axisMin, MaxIn: axisMax, MinOut: axisMin, MaxOut: axisMax})
+	}
+	for range p.triggers.Count {
+		axes = append(axes, joystick.Constraint{MinIn: triggerMin, MaxIn: triggerMax, MinOut: triggerMin, MaxOut: triggerMax})
+	}
+	js := joystick.UseSettings(joystick.Definitions{
+		ReportID:     1,
+		ButtonCnt:    p.buttons.Count,
+		HatSwitchCnt: p.hats.Count,
+		AxisDefs:     axes,
+	}, pads[0].Receive, nil, pads[0].desc)
+	list := make([]JoySticker, len(pads))
+	for i, j := range pads {
+		j.js = js
+		list[i] = j
+	}
+	return list, nil
+}
@@ -0,0 +1,8 @@
+//go:build !tinygo
+
+package service
+
+import "github.com/nobonobo/gamepad-emulator/protocol"
+
+// usbIdentity leaves the identity empty when not running on the device.
+func usbIdentity(info *protocol.Info) {}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"time"
+
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+// defaultWatchdogTimeout applies until the host calls SetWatchdog; hosts
+// that may stay silent longer must raise it or set 0.
+const defaultWatchdogTimeout = time.Second
+
+// LineStater reports the DTR line of the CDC port; it drops when the host
+// closes the port.
+type LineStater interface {
+	DTR() bool
+}
+
+type watchdog struct {
+	timeout time.Duration
+	last    time.Time
+	line    LineStater
+	dtr     bool
+	tripped bool
+	trips   int
+	reason  string
+}
+
+// WatchLine makes the watchdog trip when the host closes the port.
+func (j *JoyStick) WatchLine(l LineStater) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.watchdog.line = l
+	j.watchdog.dtr = l.DTR()
+}
+
+// touch records host activity and rearms the watchdog.
+func (j *JoyStick) touch(now time.Time) {
+	j.watchdog.last = now
+	j.watchdog.tripped = false
+}
+
+func (j *JoyStick) checkWatchdog(now time.Time) {
+	w := &j.watchdog
+	if w.line != nil {
+		dtr := w.line.DTR()
+		if w.dtr && !dtr {
+			j.trip("port closed")
+		}
+		w.dtr = dtr
+	}
+	if w.timeout > 0 && now.Sub(w.last) > w.timeout {
+		j.trip("timeout")
+	}
+}
+
+// trip cancels running macros and neutralizes the pads once until the
+// host is heard from again.
+func (j *JoyStick) trip(reason string) {
+	w := &j.watchdog
+	if w.tripped {
+		return
+	}
+	w.tripped = true
+	w.trips++
+	w.reason = reason
+	for len(j.macros.running) > 0 {
+		j.cancelMacro(j.macros.running[0].name)
+	}
+	for _, p := range j.pads {
+		p.Neutral()
+		p.SendState()
+	}
+	j.notify(protocol.NotifyWatchdogTripped, map[string]any{"reason": reason})
+}
+
+func (j *JoyStick) watchdogStatus(now time.Time) protocol.WatchdogStatus {
+	w := &j.watchdog
+	return protocol.WatchdogStatus{
+		Timeout:   int(w.timeout / time.Millisecond),
+		Idle:      int(now.Sub(w.last) / time.Millisecond),
+		Tripped:   w.tripped,
+		Trips:     w.trips,
+		Reason:    w.reason,
+		LineState: w.line == nil || w.dtr,
+	}
+}
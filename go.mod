@@ -2,9 +2,17 @@ module face-controller
 
 go 1.25.3
 
+require (
+	github.com/nobonobo/gamepad-emulator v0.0.0-00010101000000-000000000000
+	go.bug.st/serial v1.6.4
+	gocv.io/x/gocv v0.42.0
+)
+
 require (
 	github.com/creack/goselect v0.1.2 // indirect
-	go.bug.st/serial v1.6.4 // indirect
-	gocv.io/x/gocv v0.42.0 // indirect
+	github.com/josharian/intern v1.0.0 // indirect
+	github.com/mailru/easyjson v0.9.1 // indirect
 	golang.org/x/sys v0.19.0 // indirect
 )
+
+replace github.com/nobonobo/gamepad-emulator => ./gamepad-emulator
@@ -1,36 +1,30 @@
 package main
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/nobonobo/gamepad-emulator/jsonrpc"
+	"github.com/nobonobo/gamepad-emulator/protocol"
 	"go.bug.st/serial"
 )
 
-type Request struct {
-	ID      int            `json:"id"`
-	JsonRpc string         `json:"jsonrpc"`
-	Method  string         `json:"method"`
-	Params  map[string]any `json:"params,omitempty"`
-}
-
-type Error struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-}
-
-type Response struct {
-	ID     int `json:"id"`
-	Result any `json:"result,omitempty"`
-	Error  any `json:"error,omitempty"`
+// JoyStickService talks to one virtual gamepad of the device; Pad returns
+// the others, sharing the serial link.
+type JoyStickService struct {
+	*link
+	pad  int
+	auto bool // firmware reports changes on its own
 }
 
-type JoyStickService struct {
-	port    serial.Port
-	encoder *json.Encoder
-	decoder *json.Decoder
-	id      int
+type link struct {
+	port   serial.Port
+	client *jsonrpc.Client
+	binary bool
+	seq    uint8
+	frame  []byte
 }
 
 func NewJoyStickService(port string) (*JoyStickService, error) {
@@ -43,39 +37,91 @@ func NewJoyStickService(port string) (*JoyStickService, error) {
 	if err != nil {
 		return nil, err
 	}
-	p.SetReadTimeout(3 * time.Second)
-	encoder := json.NewEncoder(p)
-	decoder := json.NewDecoder(p)
-	return &JoyStickService{
-		port:    p,
-		encoder: encoder,
-		decoder: decoder,
-		id:      0,
-	}, nil
+	return &JoyStickService{link: &link{
+		port:   p,
+		client: jsonrpc.NewClient(p),
+	}}, nil
+}
+
+// Pad returns the service for pad i of the device. Mouse, keyboard,
+// switches, LEDs and settings are shared by all pads.
+func (js *JoyStickService) Pad(i int) *JoyStickService {
+	return &JoyStickService{link: js.link, pad: i}
 }
 
 func (js *JoyStickService) Close() error {
 	return js.port.Close()
 }
 
-func (js *JoyStickService) call(method string, params map[string]any) (any, error) {
-	js.id++
-	if err := js.encoder.Encode(&Request{
-		ID:      js.id,
-		JsonRpc: "2.0",
-		Method:  method,
-		Params:  params,
-	}); err != nil {
-		return nil, err
+func (js *JoyStickService) call(method string, params map[string]any) ([]byte, error) {
+	return js.client.Call(method, js.withPad(params))
+}
+
+// withPad adds the pad parameter; the first pad is the default.
+func (js *JoyStickService) withPad(params map[string]any) map[string]any {
+	if js.pad == 0 {
+		return params
 	}
-	var resp Response
-	if err := js.decoder.Decode(&resp); err != nil {
-		return nil, err
+	if params == nil {
+		params = map[string]any{}
+	}
+	params["pad"] = js.pad
+	return params
+}
+
+// Batch sends all calls in a single write. Setter calls built by the
+// *Call helpers are notifications and get no reply.
+func (js *JoyStickService) Batch(calls ...*jsonrpc.Call) error {
+	for _, c := range calls {
+		c.Params = js.withPad(c.Params)
+	}
+	return js.client.Batch(calls...)
+}
+
+func SetButtonCall(index int, push bool) *jsonrpc.Call {
+	return &jsonrpc.Call{
+		Method: "SetButton",
+		Params: map[string]any{"index": index, "push": push},
+		Notify: true,
+	}
+}
+
+func SetHatCall(index int, dir uint8) *jsonrpc.Call {
+	return &jsonrpc.Call{
+		Method: "SetHat",
+		Params: map[string]any{"index": index, "dir": dir},
+		Notify: true,
+	}
+}
+
+func SetAxisCall(index int, v int) *jsonrpc.Call {
+	return &jsonrpc.Call{
+		Method: "SetAxis",
+		Params: map[string]any{"index": index, "value": v},
+		Notify: true,
 	}
-	if resp.Error != nil {
-		return nil, fmt.Errorf("%v", resp)
+}
+
+func SetAxisFloatCall(index int, v float64) *jsonrpc.Call {
+	return &jsonrpc.Call{
+		Method: "SetAxisFloat",
+		Params: map[string]any{"index": index, "value": v},
+		Notify: true,
+	}
+}
+
+func SetTriggerCall(index int, v int) *jsonrpc.Call {
+	return &jsonrpc.Call{
+		Method: "SetTrigger",
+		Params: map[string]any{"index": index, "value": v},
+		Notify: true,
+	}
+}
+
+func SendStateCall() *jsonrpc.Call {
+	return &jsonrpc.Call{
+		Method: "SendState",
 	}
-	return resp.Result, nil
 }
 
 func (js *JoyStickService) Button(index int) (bool, error) {
@@ -85,7 +131,11 @@ func (js *JoyStickService) Button(index int) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	return res.(bool), nil
+	var v bool
+	if err := json.Unmarshal(res, &v); err != nil {
+		return false, err
+	}
+	return v, nil
 }
 
 func (js *JoyStickService) SetButton(index int, push bool) error {
@@ -105,7 +155,11 @@ func (js *JoyStickService) Hat(index int) (uint8, error) {
 	if err != nil {
 		return 0, err
 	}
-	return uint8(res.(float64)), nil
+	var v uint8
+	if err := json.Unmarshal(res, &v); err != nil {
+		return 0, err
+	}
+	return v, nil
 }
 
 func (js *JoyStickService) SetHat(index int, dir uint8) error {
@@ -125,7 +179,11 @@ func (js *JoyStickService) Axis(index int) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	return int(res.(float64)), nil
+	var v int
+	if err := json.Unmarshal(res, &v); err != nil {
+		return 0, err
+	}
+	return v, nil
 }
 
 func (js *JoyStickService) SetAxis(index int, v int) error {
@@ -138,9 +196,586 @@ func (js *JoyStickService) SetAxis(index int, v int) error {
 	return nil
 }
 
+// AxisFloat returns the axis position normalized to -1..1.
+func (js *JoyStickService) AxisFloat(index int) (float64, error) {
+	res, err := js.call("AxisFloat", map[string]any{
+		"index": index,
+	})
+	if err != nil {
+		return 0, err
+	}
+	var v float64
+	if err := json.Unmarshal(res, &v); err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+// SetAxisFloat sets the axis from a -1..1 value; the firmware saturates
+// values outside of it.
+func (js *JoyStickService) SetAxisFloat(index int, v float64) error {
+	if _, err := js.call("SetAxisFloat", map[string]any{
+		"index": index,
+		"value": v,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (js *JoyStickService) Trigger(index int) (int, error) {
+	res, err := js.call("Trigger", map[string]any{
+		"index": index,
+	})
+	if err != nil {
+		return 0, err
+	}
+	var v int
+	if err := json.Unmarshal(res, &v); err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+func (js *JoyStickService) SetTrigger(index int, v int) error {
+	if _, err := js.call("SetTrigger", map[string]any{
+		"index": index,
+		"value": v,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (js *JoyStickService) GetState() (*protocol.GamepadState, error) {
+	res, err := js.call("GetState", nil)
+	if err != nil {
+		return nil, err
+	}
+	var v protocol.GamepadState
+	if err := json.Unmarshal(res, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (js *JoyStickService) SetState(state *protocol.GamepadState, send bool) error {
+	if _, err := js.call("SetState", map[string]any{
+		"state": state,
+		"send":  send,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func SetStateCall(state *protocol.GamepadState, send bool) *jsonrpc.Call {
+	return &jsonrpc.Call{
+		Method: "SetState",
+		Params: map[string]any{"state": state, "send": send},
+		Notify: true,
+	}
+}
+
+// Hello exchanges protocol versions and returns the firmware description.
+func (js *JoyStickService) Hello() (*protocol.Info, error) {
+	res, err := js.call("Hello", map[string]any{
+		"protocol": protocol.Version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("incompatible firmware: %w", err)
+	}
+	var v protocol.Info
+	if err := json.Unmarshal(res, &v); err != nil {
+		return nil, err
+	}
+	if v.Protocol != protocol.Version {
+		return nil, fmt.Errorf("incompatible firmware %s: protocol version %d, host requires %d", v.Firmware, v.Protocol, protocol.Version)
+	}
+	return &v, nil
+}
+
+// UseBinary switches state updates to binary frames. Firmware without
+// binary support keeps the link in JSON mode and an error is returned.
+func (js *JoyStickService) UseBinary() error {
+	if _, err := js.call("SetEncoding", map[string]any{
+		"encoding": protocol.EncodingBinary,
+	}); err != nil {
+		return err
+	}
+	js.binary = true
+	return nil
+}
+
+func (js *JoyStickService) FrameStats() (*protocol.FrameStats, error) {
+	res, err := js.call("FrameStats", nil)
+	if err != nil {
+		return nil, err
+	}
+	var v protocol.FrameStats
+	if err := json.Unmarshal(res, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Update applies the state and sends a report, as a binary frame when the
+// link has been switched with UseBinary. After SetReporting with onChange
+// the firmware sends the report itself.
+func (js *JoyStickService) Update(state *protocol.GamepadState) error {
+	if !js.binary {
+		return js.SetState(state, !js.auto)
+	}
+	payload, err := state.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	js.seq++
+	typ, padTyp := protocol.FrameSetStateSend, protocol.FrameSetPadStateSend
+	if js.auto {
+		typ, padTyp = protocol.FrameSetState, protocol.FrameSetPadState
+	}
+	if js.pad != 0 {
+		typ = padTyp
+		payload = append([]byte{byte(js.pad)}, payload...)
+	}
+	js.frame = protocol.AppendFrame(js.frame[:0], typ, js.seq, payload)
+	return js.client.WriteRaw(js.frame)
+}
+
+// SetWatchdog sets how long the firmware waits for the host before it
+// neutralizes the gamepad; zero disables the timeout.
+func (js *JoyStickService) SetWatchdog(timeout time.Duration) error {
+	if _, err := js.call("SetWatchdog", map[string]any{
+		"timeout": timeout.Milliseconds(),
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (js *JoyStickService) WatchdogStatus() (*protocol.WatchdogStatus, error) {
+	res, err := js.call("WatchdogStatus", nil)
+	if err != nil {
+		return nil, err
+	}
+	var v protocol.WatchdogStatus
+	if err := json.Unmarshal(res, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
 func (js *JoyStickService) SendState() error {
 	if _, err := js.call("SendState", nil); err != nil {
 		return err
 	}
 	return nil
 }
+
+// OnSwitch registers f for on-board switch changes reported by the
+// firmware. Like all notification handlers, f runs on the client's reader
+// goroutine and must not block.
+func (js *JoyStickService) OnSwitch(f func(index int, pressed bool)) {
+	js.client.HandleNotification(protocol.NotifySwitch, func(params map[string]any) {
+		index, ok := params["index"].(float64)
+		if !ok {
+			return
+		}
+		pressed, _ := params["pressed"].(bool)
+		f(int(index), pressed)
+	})
+}
+
+// OnWatchdogTripped registers f for firmware watchdog trips.
+func (js *JoyStickService) OnWatchdogTripped(f func(reason string)) {
+	js.client.HandleNotification(protocol.NotifyWatchdogTripped, func(params map[string]any) {
+		reason, _ := params["reason"].(string)
+		f(reason)
+	})
+}
+
+// OnUSB registers f for USB bus suspend and resume by the PC.
+func (js *JoyStickService) OnUSB(f func(suspended bool)) {
+	js.client.HandleNotification(protocol.NotifyUSBSuspend, func(map[string]any) { f(true) })
+	js.client.HandleNotification(protocol.NotifyUSBResume, func(map[string]any) { f(false) })
+}
+
+// OnOutputReport registers f for output reports the PC sends to the
+// gamepad, e.g. from force feedback aware games.
+func (js *JoyStickService) OnOutputReport(f func(reportID int, data []byte)) {
+	js.client.HandleNotification(protocol.NotifyOutputReport, func(params map[string]any) {
+		id, _ := params["reportId"].(float64)
+		s, _ := params["data"].(string)
+		data, err := hex.DecodeString(s)
+		if err != nil {
+			return
+		}
+		f(int(id), data)
+	})
+}
+
+func (js *JoyStickService) Switches() ([]protocol.SwitchStatus, error) {
+	res, err := js.call("Switches", nil)
+	if err != nil {
+		return nil, err
+	}
+	var v protocol.SwitchStatuses
+	if err := json.Unmarshal(res, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// SetSwitchButton maps an on-board switch to a gamepad button; -1 only
+// reports it to the host.
+func (js *JoyStickService) SetSwitchButton(index, button int) error {
+	if _, err := js.call("SetSwitchButton", map[string]any{
+		"index":  index,
+		"button": button,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (js *JoyStickService) LEDs() ([]protocol.LEDStatus, error) {
+	res, err := js.call("LEDs", nil)
+	if err != nil {
+		return nil, err
+	}
+	var v protocol.LEDStatuses
+	if err := json.Unmarshal(res, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// SetLED turns an LED on or off, blinking with the given period when it is
+// non-zero.
+func (js *JoyStickService) SetLED(index int, on bool, blink time.Duration) error {
+	params := map[string]any{"index": index, "on": on}
+	if blink > 0 {
+		params["blink"] = blink.Milliseconds()
+	}
+	if _, err := js.call("SetLED", params); err != nil {
+		return err
+	}
+	return nil
+}
+
+// LEDPattern plays alternating on and off times on an LED. An empty
+// pattern returns the LED to its firmware function.
+func (js *JoyStickService) LEDPattern(index int, pattern []time.Duration, repeat bool) error {
+	steps := make([]int64, len(pattern))
+	for i, d := range pattern {
+		steps[i] = d.Milliseconds()
+	}
+	if _, err := js.call("LEDPattern", map[string]any{
+		"index":   index,
+		"pattern": steps,
+		"repeat":  repeat,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MacroStep changes the given inputs and holds them for Duration. Inputs
+// keep their values until a later step changes them or the macro ends.
+type MacroStep struct {
+	Axes     map[int]int   `json:"axes,omitempty"`
+	Triggers map[int]int   `json:"triggers,omitempty"`
+	Buttons  map[int]bool  `json:"buttons,omitempty"`
+	Hats     map[int]int   `json:"hats,omitempty"`
+	Duration time.Duration `json:"-"`
+}
+
+func (s MacroStep) MarshalJSON() ([]byte, error) {
+	type step MacroStep
+	return json.Marshal(struct {
+		step
+		MS int64 `json:"ms"`
+	}{step(s), s.Duration.Milliseconds()})
+}
+
+// DefineMacro uploads a macro, replacing one with the same name.
+func (js *JoyStickService) DefineMacro(name string, steps ...MacroStep) error {
+	if _, err := js.call("DefineMacro", map[string]any{
+		"name":  name,
+		"steps": steps,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RunMacro starts a macro on the device and runs it repeat more times
+// after the first.
+func (js *JoyStickService) RunMacro(name string, repeat int) error {
+	if _, err := js.call("RunMacro", map[string]any{
+		"name":   name,
+		"repeat": repeat,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func RunMacroCall(name string) *jsonrpc.Call {
+	return &jsonrpc.Call{
+		Method: "RunMacro",
+		Params: map[string]any{"name": name},
+		Notify: true,
+	}
+}
+
+func (js *JoyStickService) CancelMacro(name string) (bool, error) {
+	res, err := js.call("CancelMacro", map[string]any{"name": name})
+	if err != nil {
+		return false, err
+	}
+	var v bool
+	if err := json.Unmarshal(res, &v); err != nil {
+		return false, err
+	}
+	return v, nil
+}
+
+func (js *JoyStickService) Macros() ([]protocol.MacroStatus, error) {
+	res, err := js.call("Macros", nil)
+	if err != nil {
+		return nil, err
+	}
+	var v protocol.MacroStatuses
+	if err := json.Unmarshal(res, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// OnMacroDone registers f for macros that finished or were cancelled on
+// any pad.
+func (js *JoyStickService) OnMacroDone(f func(name string, cancelled bool)) {
+	js.client.HandleNotification(protocol.NotifyMacroDone, func(params map[string]any) {
+		name, _ := params["name"].(string)
+		cancelled, _ := params["cancelled"].(bool)
+		f(name, cancelled)
+	})
+}
+
+func (js *JoyStickService) ButtonModes() ([]protocol.ButtonMode, error) {
+	res, err := js.call("ButtonModes", nil)
+	if err != nil {
+		return nil, err
+	}
+	var v protocol.ButtonModes
+	if err := json.Unmarshal(res, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// SetButtonMode sets a button to protocol.ButtonNormal, ButtonToggle or
+// ButtonTurbo; rate is the turbo rate in presses per second, 0 for the
+// firmware default.
+func (js *JoyStickService) SetButtonMode(index int, mode string, rate float64) error {
+	params := map[string]any{"index": index, "mode": mode}
+	if rate > 0 {
+		params["rate"] = rate
+	}
+	if _, err := js.call("SetButtonMode", params); err != nil {
+		return err
+	}
+	return nil
+}
+
+// PressFor presses a button on the device for d and reports it at once.
+// On a toggle button this flips it.
+func (js *JoyStickService) PressFor(index int, d time.Duration) error {
+	if _, err := js.call("PressFor", map[string]any{
+		"index": index,
+		"ms":    d.Milliseconds(),
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetInterpolation makes the firmware move axes smoothly toward the values
+// sent by the host, reporting on its own while they move. Zero rate and
+// interval select the firmware defaults.
+func (js *JoyStickService) SetInterpolation(mode string, rate float64, interval time.Duration) error {
+	params := map[string]any{"mode": mode}
+	if rate > 0 {
+		params["rate"] = rate
+	}
+	if interval > 0 {
+		params["interval"] = interval.Milliseconds()
+	}
+	if _, err := js.call("SetInterpolation", params); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetReporting makes the firmware send reports on change and/or every
+// interval, merging updates that arrive within one USB poll interval.
+func (js *JoyStickService) SetReporting(onChange bool, interval time.Duration) error {
+	if _, err := js.call("SetReporting", map[string]any{
+		"onChange": onChange,
+		"interval": interval.Milliseconds(),
+	}); err != nil {
+		return err
+	}
+	js.auto = onChange
+	return nil
+}
+
+func (js *JoyStickService) ReportStats() (*protocol.ReportStats, error) {
+	res, err := js.call("ReportStats", nil)
+	if err != nil {
+		return nil, err
+	}
+	var v protocol.ReportStats
+	if err := json.Unmarshal(res, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// MouseMove moves the device's mouse relatively; the firmware splits large
+// moves into several reports and rejects deltas beyond ±32767.
+func (js *JoyStickService) MouseMove(dx, dy, wheel int) error {
+	params := map[string]any{"dx": dx, "dy": dy}
+	if wheel != 0 {
+		params["wheel"] = wheel
+	}
+	if _, err := js.call("MouseMove", params); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (js *JoyStickService) MouseButton(index int, push bool) error {
+	if _, err := js.call("MouseButton", map[string]any{"index": index, "push": push}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MouseAbs moves the absolute pointer to x, y in 0..1 of the screen.
+func (js *JoyStickService) MouseAbs(x, y float64) error {
+	if _, err := js.call("MouseAbs", map[string]any{"x": x, "y": y}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// KeyDown presses a key given by name ("enter", "ctrl", "f5") or as a
+// single character.
+func (js *JoyStickService) KeyDown(key string) error {
+	if _, err := js.call("KeyDown", map[string]any{"key": key}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (js *JoyStickService) KeyUp(key string) error {
+	if _, err := js.call("KeyUp", map[string]any{"key": key}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// TypeString makes the firmware type text on a US layout.
+func (js *JoyStickService) TypeString(text string) error {
+	if _, err := js.call("TypeString", map[string]any{"text": text}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (js *JoyStickService) Profiles() ([]protocol.ProfileStatus, error) {
+	res, err := js.call("Profiles", nil)
+	if err != nil {
+		return nil, err
+	}
+	var v protocol.ProfileStatuses
+	if err := json.Unmarshal(res, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// SetProfile selects the device profile; the device enumerates with it
+// after the next reset or replug.
+func (js *JoyStickService) SetProfile(name string) error {
+	if _, err := js.call("SetProfile", map[string]any{"name": name}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (js *JoyStickService) GetSettings() (*protocol.Settings, error) {
+	res, err := js.call("GetSettings", nil)
+	if err != nil {
+		return nil, err
+	}
+	var v protocol.Settings
+	if err := json.Unmarshal(res, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// SetSettings applies settings on the device without saving them.
+func (js *JoyStickService) SetSettings(s *protocol.Settings) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	var params map[string]any
+	if err := json.Unmarshal(b, &params); err != nil {
+		return err
+	}
+	if _, err := js.call("SetSettings", params); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SaveSettings stores the device's current settings in its flash.
+func (js *JoyStickService) SaveSettings() error {
+	if _, err := js.call("SaveSettings", nil); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FactoryReset erases the saved settings and restores the defaults.
+func (js *JoyStickService) FactoryReset() error {
+	if _, err := js.call("FactoryReset", nil); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetIdentity sets the product string and serial number the device uses
+// after the next reset and saves them; empty values select the firmware
+// defaults.
+func (js *JoyStickService) SetIdentity(product, serial string) error {
+	if _, err := js.call("SetSettings", map[string]any{"product": product, "serial": serial}); err != nil {
+		return err
+	}
+	return js.SaveSettings()
+}
+
+// SetPads sets how many gamepads the device presents after the next reset
+// and saves it.
+func (js *JoyStickService) SetPads(n int) error {
+	if _, err := js.call("SetSettings", map[string]any{"pads": n}); err != nil {
+		return err
+	}
+	return js.SaveSettings()
+}
@@ -5,12 +5,17 @@ import (
 	"image"
 	"image/color"
 	"log"
+	"math"
+	"slices"
 	"time"
 
+	"github.com/nobonobo/gamepad-emulator/protocol"
 	"gocv.io/x/gocv"
-	"gocv.io/x/gocv/contrib"
 )
 
+// togglePress is how long a toggle request holds the toggle button.
+const togglePress = 50 * time.Millisecond
+
 const (
 	haarCascadeFile = "haarcascade_frontalface_default.xml"
 	//haarCascadeFile = "haarcascade_eye_tree_eyeglasses.xml"
@@ -22,14 +27,53 @@ const (
 func main() {
 	capture := 1
 	port := ""
+	serialNumber := ""
 	view := false
 	disable := false
 	min, max := 100, 200
+	binary := false
+	gain := 2.5
+	watchdog := time.Second
+	statusIndex := 1
+	smooth := protocol.InterpolateOff
+	smoothRate := 0.0
+	reportOnChange := false
+	reportInterval := time.Duration(0)
+	mouseSpeed := 20.0
+	profile := ""
+	saveSettings := false
+	pads := 1
+	mapping := Mapping{AxisX: 2, AxisY: 3, ToggleButton: 0, Target: TargetGamepad}
 	flag.BoolVar(&disable, "n", disable, "no window")
 	flag.BoolVar(&view, "view", view, "show window")
 	flag.IntVar(&capture, "capture", capture, "capture device index")
-	flag.StringVar(&port, "port", port, "serial port name")
+	flag.StringVar(&port, "port", port, "serial port name (default: find the device by -serial)")
+	flag.StringVar(&serialNumber, "serial", serialNumber, "USB serial number of the device to use when no -port is given")
+	flag.BoolVar(&binary, "binary", binary, "send state updates as binary frames")
+	flag.DurationVar(&watchdog, "watchdog", watchdog, "neutralize the gamepad after this long without updates (0 disables)")
+	flag.Float64Var(&gain, "gain", gain, "axis deflection per half frame of face movement")
+	flag.IntVar(&mapping.AxisX, "axis-x", mapping.AxisX, "axis index driven by horizontal face position")
+	flag.IntVar(&mapping.AxisY, "axis-y", mapping.AxisY, "axis index driven by vertical face position")
+	flag.IntVar(&mapping.ToggleButton, "toggle-button", mapping.ToggleButton, "button index toggled by the 'a' key")
+	flag.Var(&mapping.Triggers, "trigger", "drive a trigger from a signal (x, y, lean) as signal:index[:gain], repeatable")
+	flag.Var(&mapping.Switches, "switch", "bind an on-board switch as index:action (recenter, pause, toggle), index:button:n or index:key:name, repeatable")
+	flag.StringVar(&mapping.Target, "target", mapping.Target, "what the face position drives: gamepad, mouse or mouse-abs")
+	flag.Float64Var(&mouseSpeed, "mouse-speed", mouseSpeed, "mouse movement per frame at full deflection with -target mouse")
+	flag.StringVar(&smooth, "smooth", smooth, "firmware axis interpolation between updates: off, linear or damped")
+	flag.Float64Var(&smoothRate, "smooth-rate", smoothRate, "interpolation rate: units/s for linear, rad/s for damped (0 uses the firmware default)")
+	flag.BoolVar(&reportOnChange, "report-on-change", reportOnChange, "let the firmware send a report whenever the state changes")
+	flag.DurationVar(&reportInterval, "report-interval", reportInterval, "let the firmware resend the report at this interval (0 disables)")
+	flag.StringVar(&profile, "profile", profile, "select the device profile (gamepad, flightstick, wheel); takes effect after replugging")
+	flag.BoolVar(&saveSettings, "save-settings", saveSettings, "save the watchdog and button settings in the device's flash")
+	flag.IntVar(&pads, "pads", pads, "number of players; faces drive pads 0, 1, ... from left to right")
+	flag.IntVar(&statusIndex, "status-led", statusIndex, "firmware LED index showing the tracking state (-1 disables)")
 	flag.Parse()
+	if pads < 1 {
+		log.Fatalf("Invalid pads: %d\n", pads)
+	}
+	if pads > 1 && mapping.Target != TargetGamepad {
+		log.Fatalf("Invalid mapping: target %s is shared by all players, use -pads 1\n", mapping.Target)
+	}
 	webcam, err := gocv.OpenVideoCapture(capture)
 	if err != nil {
 		log.Fatalf("Error opening video capture device: %v\n", err)
@@ -40,34 +84,206 @@ func main() {
 		log.Fatalf("Error reading cascade file: %v\n", haarCascadeFile)
 	}
 	defer classifier.Close()
-	tracker := contrib.NewTrackerCSRT()
-	defer tracker.Close()
-	tracking := false // トラッキング状態のフラグ
-	var trackRect image.Rectangle
 	var window *gocv.Window
 	if !disable {
 		window = gocv.NewWindow("Hello")
 	}
 	img := gocv.NewMat()
 	dst := gocv.NewMat()
+	if port == "" {
+		if port, err = FindPort(serialNumber); err != nil {
+			log.Fatalf("Error finding device: %v\n", err)
+		}
+	}
 	service, err := NewJoyStickService(port)
 	if err != nil {
 		log.Fatalf("Error opening serial port: %v\n", err)
 	}
 	defer service.Close()
-	toggle := false
+	info, err := service.Hello()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	log.Printf("firmware %s (protocol %d) %s %s serial %s", info.Firmware, info.Protocol, info.Manufacturer, info.Product, info.Serial)
+	if profile != "" && profile != info.Profile {
+		if !slices.Contains(info.Methods, "SetProfile") {
+			log.Fatalf("firmware %s does not support profiles\n", info.Firmware)
+		}
+		if err := service.SetProfile(profile); err != nil {
+			log.Fatalf("Error selecting profile: %v\n", err)
+		}
+		log.Printf("profile %s selected, replug the device to apply it", profile)
+		return
+	}
+	if pads > 1 && pads > info.Pads {
+		if info.Pads == 0 {
+			log.Fatalf("firmware %s has a single gamepad\n", info.Firmware)
+		}
+		if err := service.SetPads(pads); err != nil {
+			log.Fatalf("Error setting pads: %v\n", err)
+		}
+		log.Printf("%d pads selected, replug the device to apply them", pads)
+		return
+	}
+	if err := mapping.Validate(info); err != nil {
+		log.Fatalf("Invalid mapping: %v\n", err)
+	}
+	if slices.Contains(info.Methods, "SetWatchdog") {
+		if err := service.SetWatchdog(watchdog); err != nil {
+			log.Println("watchdog:", err)
+		}
+	}
+	if binary {
+		if !slices.Contains(info.Encodings, protocol.EncodingBinary) {
+			log.Println("binary encoding unavailable: not supported by firmware")
+		} else if err := service.UseBinary(); err != nil {
+			log.Println("binary encoding unavailable:", err)
+		}
+	}
+	service.OnWatchdogTripped(func(reason string) {
+		log.Println("firmware watchdog tripped:", reason)
+	})
+	service.OnUSB(func(suspended bool) {
+		log.Println("usb suspended:", suspended)
+	})
+	service.OnOutputReport(func(reportID int, data []byte) {
+		log.Printf("output report %d: % x", reportID, data)
+	})
+	const N = 2
+	players := make([]*player, pads)
+	for i := range players {
+		pad := service.Pad(i)
+		if smooth != protocol.InterpolateOff {
+			if !slices.Contains(info.Methods, "SetInterpolation") {
+				log.Println("interpolation unavailable: not supported by firmware")
+			} else if err := pad.SetInterpolation(smooth, smoothRate, 0); err != nil {
+				log.Fatalf("Error setting interpolation: %v\n", err)
+			}
+		}
+		if reportOnChange || reportInterval > 0 {
+			if !slices.Contains(info.Methods, "SetReporting") {
+				log.Println("automatic reporting unavailable: not supported by firmware")
+			} else if err := pad.SetReporting(reportOnChange, reportInterval); err != nil {
+				log.Fatalf("Error setting reporting: %v\n", err)
+			}
+		}
+		if err := pad.SetButtonMode(mapping.ToggleButton, protocol.ButtonToggle, 0); err != nil {
+			log.Fatalf("Error setting toggle button: %v\n", err)
+		}
+		state, err := pad.GetState()
+		if err != nil {
+			log.Fatalf("Error reading gamepad state: %v\n", err)
+		}
+		// the firmware latches the toggle button; the host only sends presses
+		state.Buttons[mapping.ToggleButton] = false
+		players[i] = newPlayer(pad, state, N)
+		defer players[i].Close()
+	}
+	var status *statusLED
+	if statusIndex >= 0 && slices.Contains(info.Methods, "LEDs") {
+		leds, err := service.LEDs()
+		if err != nil {
+			log.Fatalf("Error reading LEDs: %v\n", err)
+		}
+		if statusIndex >= len(leds) {
+			log.Fatalf("Invalid status-led: index %d out of range, firmware has %d\n", statusIndex, len(leds))
+		}
+		status = &statusLED{service: service, index: statusIndex}
+		defer service.LEDPattern(statusIndex, nil, false)
+	}
+	type switchEvent struct {
+		index   int
+		pressed bool
+	}
+	switches := make(chan switchEvent, 8)
+	if len(mapping.Switches) > 0 {
+		status, err := service.Switches()
+		if err != nil {
+			log.Fatalf("Error reading switches: %v\n", err)
+		}
+		for _, m := range mapping.Switches {
+			if m.Index < 0 || m.Index >= len(status) {
+				log.Fatalf("Invalid mapping: switch: index %d out of range, firmware has %d\n", m.Index, len(status))
+			}
+			if m.Action == ActionButton {
+				if err := service.SetSwitchButton(m.Index, m.Button); err != nil {
+					log.Fatalf("Error mapping switch: %v\n", err)
+				}
+			}
+		}
+		service.OnSwitch(func(index int, pressed bool) {
+			select {
+			case switches <- switchEvent{index, pressed}:
+			default:
+			}
+		})
+	}
+	toggle := func() {
+		for _, p := range players {
+			if err := p.pad.PressFor(mapping.ToggleButton, togglePress); err != nil {
+				log.Println("toggle:", err)
+			}
+		}
+	}
+	if saveSettings {
+		if !slices.Contains(info.Methods, "SaveSettings") {
+			log.Println("saving settings unavailable: not supported by firmware")
+		} else if err := service.SaveSettings(); err != nil {
+			log.Fatalf("Error saving settings: %v\n", err)
+		}
+	}
+	state := players[0].state
+	neutral := &protocol.GamepadState{
+		Axes:     make([]int, len(state.Axes)),
+		Triggers: make([]int, len(state.Triggers)),
+		Buttons:  make([]bool, len(state.Buttons)),
+		Hats:     make([]int, len(state.Hats)),
+	}
+	for i := range neutral.Hats {
+		neutral.Hats[i] = 8 // center
+	}
+	paused := false
 	ticker := time.NewTicker(time.Second / 30)
 	tick := 0
-	const N = 2
-	dx := make([]float64, N)
-	dy := make([]float64, N)
 	for {
 		select {
+		case ev := <-switches:
+			m, ok := mapping.Switches.Lookup(ev.index)
+			if !ok {
+				continue
+			}
+			if m.Action == ActionKey {
+				press := service.KeyUp
+				if ev.pressed {
+					press = service.KeyDown
+				}
+				if err := press(m.Key); err != nil {
+					log.Println("key:", err)
+				}
+				continue
+			}
+			if !ev.pressed {
+				continue
+			}
+			switch m.Action {
+			case ActionRecenter:
+				for i, p := range players {
+					p.cx, p.cy = p.lastX, p.lastY
+					log.Printf("pad %d recentered at %.2f,%.2f", i, p.cx, p.cy)
+				}
+			case ActionPause:
+				paused = !paused
+				log.Println("paused:", paused)
+			case ActionToggle:
+				toggle()
+			}
 		case <-ticker.C:
 			tick++
 			if tick%30 == 0 {
-				if trackRect.Dx() > max || trackRect.Dx() < min {
-					tracking = false
+				for _, p := range players {
+					if p.rect.Dx() > max || p.rect.Dx() < min {
+						p.lose()
+					}
 				}
 			}
 			if !disable {
@@ -78,12 +294,13 @@ func main() {
 				switch v {
 				default:
 				case 0x20:
-					tracking = false
+					for _, p := range players {
+						p.tracking = false
+					}
 				case 27, 113:
 					return
 				case 97:
-					toggle = !toggle
-					service.SetButton(0, toggle)
+					toggle()
 				}
 			}
 			if ok := webcam.Read(&img); !ok || img.Empty() {
@@ -99,65 +316,94 @@ func main() {
 					gocv.Rectangle(&dst, image.Rect(0, 0, dst.Cols(), dst.Rows()), color.RGBA{G: 255, A: 255}, -1)
 				}
 			}
-			if !tracking {
-				rects := classifier.DetectMultiScale(img)
-				if len(rects) > 0 {
-					// 1番大きい顔を追跡対象に選ぶ例
-					maxIdx := 0
-					maxArea := 0
-					for i, r := range rects {
-						area := r.Dx() * r.Dy()
-						if area > maxArea {
-							maxArea = area
-							maxIdx = i
-						}
-					}
-					trackRect = rects[maxIdx]
-
-					// トラッカー初期化
-					tracker.Init(img, trackRect)
-					tracking = true
+			idle := false
+			for _, p := range players {
+				if !p.tracking {
+					idle = true
+					continue
 				}
-			} else {
 				// トラッキング更新
-				newRect, ok := tracker.Update(img)
-				if ok {
-					trackRect = newRect
-					if !disable {
-						gocv.Rectangle(&dst, trackRect, (color.RGBA{0, 0, 255, 0}), 3)
-					}
-				} else {
+				newRect, ok := p.tracker.Update(img)
+				if !ok {
 					// トラッキング失敗時 リセット
-					tracking = false
+					p.lose()
+					continue
+				}
+				p.rect = newRect
+				if !disable {
+					gocv.Rectangle(&dst, p.rect, (color.RGBA{0, 0, 255, 0}), 3)
 				}
 			}
+			if idle {
+				assignFaces(players, classifier.DetectMultiScale(img), img)
+			}
 			if !disable {
 				window.IMShow(dst)
 			}
-			const K = 64.0
-			dx = append(dx[1:], 0)
-			dx[N-1] = K * (float64(trackRect.Max.X+trackRect.Min.X)/2 - float64(img.Size()[1]/2))
-			dy[N-1] = K * (float64(trackRect.Max.Y+trackRect.Min.Y)/2 - float64(img.Size()[0]/2))
-			adx := 0.0
-			ady := 0.0
-			for _, v := range dx {
-				adx += v
-			}
-			for _, v := range dy {
-				ady += v
-			}
-			adx /= N
-			ady /= N
-			//log.Println(dx, dy)
-			if err := service.SetAxis(2, int(adx)); err != nil {
-				log.Println(err)
+			switch {
+			case paused:
+				status.Set(StatusPaused)
+			case slices.ContainsFunc(players, func(p *player) bool { return p.tracking }):
+				status.Set(StatusTracking)
+			case slices.ContainsFunc(players, func(p *player) bool { return time.Since(p.lostAt) < lostHold }):
+				status.Set(StatusLost)
+			default:
+				status.Set(StatusSearching)
 			}
-			if err := service.SetAxis(3, int(ady)); err != nil {
-				log.Println(err)
-			}
-			if err := service.SendState(); err != nil {
-				log.Println(err)
+			w, h := float64(img.Size()[1]), float64(img.Size()[0])
+			for _, p := range players {
+				p.lastX = (float64(p.rect.Max.X+p.rect.Min.X) - w) / w
+				p.lastY = (float64(p.rect.Max.Y+p.rect.Min.Y) - h) / h
+				signals := map[string]float64{
+					SignalX: p.lastX - p.cx,
+					SignalY: p.lastY - p.cy,
+				}
+				if p.tracking && p.baseWidth > 0 {
+					signals[SignalLean] = float64(p.rect.Dx())/float64(p.baseWidth) - 1
+				}
+				p.dx = append(p.dx[1:], gain*signals[SignalX])
+				p.dy = append(p.dy[1:], gain*signals[SignalY])
+				adx := 0.0
+				ady := 0.0
+				for _, v := range p.dx {
+					adx += v
+				}
+				for _, v := range p.dy {
+					ady += v
+				}
+				adx /= N
+				ady /= N
+				//log.Println(p.dx, p.dy)
+				switch {
+				case paused || !p.tracking:
+				case mapping.Target == TargetMouse:
+					if err := p.pad.MouseMove(int(adx*mouseSpeed), int(ady*mouseSpeed), 0); err != nil {
+						log.Println(err)
+					}
+				case mapping.Target == TargetMouseAbs:
+					if err := p.pad.MouseAbs(clamp01(0.5+adx/2), clamp01(0.5+ady/2)); err != nil {
+						log.Println(err)
+					}
+				}
+				if mapping.Target == TargetGamepad {
+					p.state.Axes[mapping.AxisX] = info.Layout.AxisValue(adx)
+					p.state.Axes[mapping.AxisY] = info.Layout.AxisValue(ady)
+				}
+				for _, t := range mapping.Triggers {
+					p.state.Triggers[t.Index] = t.Value(signals, info.Layout)
+				}
+				update := p.state
+				if paused {
+					update = neutral
+				}
+				if err := p.pad.Update(update); err != nil {
+					log.Println(err)
+				}
 			}
 		}
 	}
 }
+
+func clamp01(v float64) float64 {
+	return math.Min(math.Max(v, 0), 1)
+}
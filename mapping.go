@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nobonobo/gamepad-emulator/hid"
+	"github.com/nobonobo/gamepad-emulator/protocol"
+)
+
+// Signals produced by the face tracker, roughly in -1..1.
+const (
+	SignalX    = "x"    // horizontal face position
+	SignalY    = "y"    // vertical face position
+	SignalLean = "lean" // face size relative to when tracking started
+)
+
+var signals = []string{SignalX, SignalY, SignalLean}
+
+// TriggerMapping drives an analog trigger from a signal. The trigger is
+// released at 0 and fully pressed at 1/Gain.
+type TriggerMapping struct {
+	Index  int
+	Signal string
+	Gain   float64
+}
+
+func (t TriggerMapping) Value(signals map[string]float64, layout protocol.Layout) int {
+	return layout.TriggerValue(t.Gain * signals[t.Signal])
+}
+
+// triggerFlags parses -trigger values of the form signal:index[:gain].
+type triggerFlags []TriggerMapping
+
+func (f *triggerFlags) String() string {
+	s := make([]string, len(*f))
+	for i, t := range *f {
+		s[i] = fmt.Sprintf("%s:%d:%g", t.Signal, t.Index, t.Gain)
+	}
+	return strings.Join(s, ",")
+}
+
+func (f *triggerFlags) Set(v string) error {
+	parts := strings.Split(v, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return fmt.Errorf("want signal:index[:gain], got %q", v)
+	}
+	t := TriggerMapping{Signal: parts[0], Gain: 1}
+	index, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return fmt.Errorf("invalid trigger index: %w", err)
+	}
+	t.Index = index
+	if len(parts) == 3 {
+		if t.Gain, err = strconv.ParseFloat(parts[2], 64); err != nil {
+			return fmt.Errorf("invalid trigger gain: %w", err)
+		}
+	}
+	*f = append(*f, t)
+	return nil
+}
+
+// Actions bound to the on-board switches of the firmware.
+const (
+	ActionRecenter = "recenter" // treat the current face position as center
+	ActionPause    = "pause"    // hold the gamepad neutral until pressed again
+	ActionToggle   = "toggle"   // same as the 'a' key
+	ActionButton   = "button"   // press a gamepad button in firmware
+	ActionKey      = "key"      // hold a keyboard key while pressed
+)
+
+var actions = []string{ActionRecenter, ActionPause, ActionToggle, ActionButton, ActionKey}
+
+// Targets the face position can drive.
+const (
+	TargetGamepad  = "gamepad"   // the mapped gamepad axes
+	TargetMouse    = "mouse"     // relative mouse movement
+	TargetMouseAbs = "mouse-abs" // absolute pointer position
+)
+
+var targets = []string{TargetGamepad, TargetMouse, TargetMouseAbs}
+
+// SwitchMapping binds an on-board switch to an action. Button is only used
+// by ActionButton and Key by ActionKey.
+type SwitchMapping struct {
+	Index  int
+	Action string
+	Button int
+	Key    string
+}
+
+// switchFlags parses -switch values of the form index:action[:button|key].
+type switchFlags []SwitchMapping
+
+func (f *switchFlags) String() string {
+	s := make([]string, len(*f))
+	for i, m := range *f {
+		s[i] = fmt.Sprintf("%d:%s", m.Index, m.Action)
+		switch m.Action {
+		case ActionButton:
+			s[i] += fmt.Sprintf(":%d", m.Button)
+		case ActionKey:
+			s[i] += ":" + m.Key
+		}
+	}
+	return strings.Join(s, ",")
+}
+
+func (f *switchFlags) Set(v string) error {
+	parts := strings.Split(v, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return fmt.Errorf("want index:action[:button|key], got %q", v)
+	}
+	m := SwitchMapping{Action: parts[1]}
+	index, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid switch index: %w", err)
+	}
+	m.Index = index
+	if (m.Action == ActionButton || m.Action == ActionKey) != (len(parts) == 3) {
+		return fmt.Errorf("want index:%s:button, index:%s:key or index:action, got %q", ActionButton, ActionKey, v)
+	}
+	switch m.Action {
+	case ActionButton:
+		if m.Button, err = strconv.Atoi(parts[2]); err != nil {
+			return fmt.Errorf("invalid switch button: %w", err)
+		}
+	case ActionKey:
+		m.Key = parts[2]
+	}
+	*f = append(*f, m)
+	return nil
+}
+
+// Lookup returns the mapping of switch index.
+func (f switchFlags) Lookup(index int) (SwitchMapping, bool) {
+	for _, m := range f {
+		if m.Index == index {
+			return m, true
+		}
+	}
+	return SwitchMapping{}, false
+}
+
+// Mapping assigns face tracking signals to gamepad inputs.
+type Mapping struct {
+	AxisX        int
+	AxisY        int
+	ToggleButton int
+	Triggers     triggerFlags
+	Switches     switchFlags
+	Target       string
+}
+
+// Validate checks the mapping against the layout reported by the firmware.
+func (m *Mapping) Validate(info *protocol.Info) error {
+	type check struct {
+		name  string
+		index int
+		count int
+	}
+	checks := []check{
+		{"axis-x", m.AxisX, info.Layout.Axes},
+		{"axis-y", m.AxisY, info.Layout.Axes},
+		{"toggle-button", m.ToggleButton, info.Layout.Buttons},
+	}
+	for _, t := range m.Triggers {
+		if !slices.Contains(signals, t.Signal) {
+			return fmt.Errorf("trigger: unknown signal %q, want one of %v", t.Signal, signals)
+		}
+		checks = append(checks, check{"trigger", t.Index, info.Layout.Triggers})
+	}
+	keyboard := false
+	for _, m := range m.Switches {
+		if !slices.Contains(actions, m.Action) {
+			return fmt.Errorf("switch: unknown action %q, want one of %v", m.Action, actions)
+		}
+		switch m.Action {
+		case ActionButton:
+			checks = append(checks, check{"switch button", m.Button, info.Layout.Buttons})
+		case ActionKey:
+			if _, ok := hid.KeyByName(m.Key); !ok {
+				return fmt.Errorf("switch: unknown key %q", m.Key)
+			}
+			keyboard = true
+		}
+	}
+	if !slices.Contains(targets, m.Target) {
+		return fmt.Errorf("target: unknown target %q, want one of %v", m.Target, targets)
+	}
+	required := []string{"GetState", "SetState", "SetButtonMode", "PressFor"}
+	switch m.Target {
+	case TargetMouse:
+		required = append(required, "MouseMove")
+	case TargetMouseAbs:
+		required = append(required, "MouseAbs")
+	}
+	if keyboard {
+		required = append(required, "KeyDown", "KeyUp")
+	}
+	if len(m.Switches) > 0 && !slices.Contains(info.Methods, "Switches") {
+		return fmt.Errorf("firmware %s has no on-board switches", info.Firmware)
+	}
+	for _, v := range checks {
+		if v.index < 0 || v.index >= v.count {
+			return fmt.Errorf("%s: index %d out of range, firmware has %d", v.name, v.index, v.count)
+		}
+	}
+	for _, name := range required {
+		if !slices.Contains(info.Methods, name) {
+			return fmt.Errorf("firmware %s does not support %s", info.Firmware, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"image"
+	"slices"
+	"time"
+
+	"github.com/nobonobo/gamepad-emulator/protocol"
+	"gocv.io/x/gocv"
+	"gocv.io/x/gocv/contrib"
+)
+
+// player is one tracked face driving one pad of the device.
+type player struct {
+	pad       *JoyStickService
+	state     *protocol.GamepadState
+	tracker   contrib.TrackerCSRT
+	tracking  bool
+	rect      image.Rectangle
+	baseWidth int // face width when tracking started
+	lostAt    time.Time
+	cx, cy    float64 // recentered position
+	lastX     float64
+	lastY     float64
+	dx, dy    []float64 // recent deflections, averaged
+}
+
+func newPlayer(pad *JoyStickService, state *protocol.GamepadState, n int) *player {
+	return &player{
+		pad:     pad,
+		state:   state,
+		tracker: contrib.NewTrackerCSRT(),
+		dx:      make([]float64, n),
+		dy:      make([]float64, n),
+	}
+}
+
+func (p *player) Close() error {
+	return p.tracker.Close()
+}
+
+func (p *player) start(img gocv.Mat, r image.Rectangle) {
+	p.tracker.Init(img, r)
+	p.rect = r
+	p.tracking = true
+	p.baseWidth = r.Dx()
+}
+
+func (p *player) lose() {
+	if p.tracking {
+		p.lostAt = time.Now()
+	}
+	p.tracking = false
+}
+
+// assignFaces starts tracking on the players without a face. It takes the
+// largest detected faces that no player tracks yet and hands them out from
+// left to right, so with one pad per player the leftmost new face gets the
+// lowest free pad.
+func assignFaces(players []*player, faces []image.Rectangle, img gocv.Mat) {
+	var idle []*player
+	for _, p := range players {
+		if !p.tracking {
+			idle = append(idle, p)
+		}
+	}
+	faces = slices.DeleteFunc(faces, func(r image.Rectangle) bool {
+		return slices.ContainsFunc(players, func(p *player) bool {
+			return p.tracking && p.rect.Overlaps(r)
+		})
+	})
+	slices.SortStableFunc(faces, func(a, b image.Rectangle) int {
+		return b.Dx()*b.Dy() - a.Dx()*a.Dy()
+	})
+	faces = faces[:min(len(faces), len(idle))]
+	slices.SortFunc(faces, func(a, b image.Rectangle) int {
+		return a.Min.X - b.Min.X
+	})
+	for i, r := range faces {
+		idle[i].start(img, r)
+	}
+}
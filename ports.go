@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"go.bug.st/serial/enumerator"
+)
+
+// USB ids of the gamepad emulator firmware.
+const (
+	deviceVID = "2786"
+	devicePID = "000a"
+)
+
+// FindPort returns the serial port of the gamepad emulator with the given
+// USB serial number. With an empty serial it returns the only connected
+// emulator.
+func FindPort(serial string) (string, error) {
+	ports, err := enumerator.GetDetailedPortsList()
+	if err != nil {
+		return "", err
+	}
+	var found []*enumerator.PortDetails
+	for _, p := range ports {
+		if !p.IsUSB || !strings.EqualFold(p.VID, deviceVID) || !strings.EqualFold(p.PID, devicePID) {
+			continue
+		}
+		if serial == "" || p.SerialNumber == serial {
+			found = append(found, p)
+		}
+	}
+	switch {
+	case len(found) == 1:
+		return found[0].Name, nil
+	case len(found) == 0 && serial != "":
+		return "", fmt.Errorf("no gamepad emulator with serial %s", serial)
+	case len(found) == 0:
+		return "", fmt.Errorf("no gamepad emulator found")
+	}
+	s := make([]string, len(found))
+	for i, p := range found {
+		s[i] = fmt.Sprintf("%s (serial %s)", p.Name, p.SerialNumber)
+	}
+	return "", fmt.Errorf("%d gamepad emulators found, select one with -serial: %s", len(found), strings.Join(s, ", "))
+}
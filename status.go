@@ -0,0 +1,47 @@
+package main
+
+import (
+	"log"
+	"time"
+)
+
+// Tracking states shown on the status LED.
+const (
+	StatusSearching = "searching" // no face found yet
+	StatusTracking  = "tracking"
+	StatusLost      = "lost" // tracking failed recently
+	StatusPaused    = "paused"
+)
+
+// lostHold is how long StatusLost is shown before going back to searching.
+const lostHold = 2 * time.Second
+
+var statusPatterns = map[string][]time.Duration{
+	StatusSearching: {500 * time.Millisecond, 500 * time.Millisecond},
+	StatusLost:      {100 * time.Millisecond, 100 * time.Millisecond},
+	StatusPaused:    {100 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond, 700 * time.Millisecond},
+}
+
+// statusLED mirrors the tracking state on a firmware LED, sending only
+// changes.
+type statusLED struct {
+	service *JoyStickService
+	index   int
+	status  string
+}
+
+func (s *statusLED) Set(status string) {
+	if s == nil || status == s.status {
+		return
+	}
+	s.status = status
+	var err error
+	if pattern, ok := statusPatterns[status]; ok {
+		err = s.service.LEDPattern(s.index, pattern, true)
+	} else {
+		err = s.service.SetLED(s.index, true, 0)
+	}
+	if err != nil {
+		log.Println("status led:", err)
+	}
+}